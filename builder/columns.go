@@ -6,7 +6,6 @@
 package builder
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -38,32 +37,39 @@ type Columns interface {
 }
 
 type columns struct {
-	str strings.Builder
+	list []string
 }
 
 func (c *columns) Add(col ...string) Columns {
-	if len(col) > 0 {
-		if c.str.Len() > 0 {
-			fmt.Fprintf(&c.str, ",%s", strings.Join(col, ","))
-		} else {
-			fmt.Fprint(&c.str, strings.Join(col, ","))
-		}
-	}
+	c.list = append(c.list, col...)
 	return c
 }
 
 func (c *columns) Len() int {
-	return c.str.Len()
+	return len(c.join())
 }
 
 func (c *columns) String() string {
-	if c.str.Len() == 0 {
+	return c.render(DefaultDialect)
+}
+
+func (c *columns) render(d Dialect) string {
+	if len(c.list) == 0 {
 		return "*"
 	}
 
-	return c.str.String()
+	out := make([]string, len(c.list))
+	for i, col := range c.list {
+		out[i] = column(d, col)
+	}
+	return strings.Join(out, ",")
 }
 
 func (c *columns) Reset() {
-	c.str.Reset()
+	c.list = nil
+}
+
+// join returns the columns as written.
+func (c *columns) join() string {
+	return strings.Join(c.list, ",")
 }
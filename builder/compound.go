@@ -0,0 +1,149 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"strings"
+)
+
+const (
+	unionKeyword     = " UNION "
+	unionAllKeyword  = " UNION ALL "
+	intersectKeyword = " INTERSECT "
+	exceptKeyword    = " EXCEPT "
+)
+
+// NewCompound create a new compound starting with the select s.
+func NewCompound(s *Select) *Compound {
+	return &Compound{
+		first:   s,
+		OrderBy: NewOrderBy(),
+	}
+}
+
+// NewUnion create a new compound of selects combined with UNION.
+func NewUnion(s *Select, selects ...*Select) *Compound {
+	return NewCompound(s).Union(selects...)
+}
+
+// NewUnionAll create a new compound of selects combined with UNION ALL.
+func NewUnionAll(s *Select, selects ...*Select) *Compound {
+	return NewCompound(s).UnionAll(selects...)
+}
+
+// Compound is the representation of selects combined with
+// UNION, UNION ALL, INTERSECT or EXCEPT.
+type Compound struct {
+	first   *Select
+	parts   []compoundPart
+	OrderBy OrderBy
+	Limit   uint64
+	Offset  uint64
+}
+
+// compoundPart is a select combined with the previous ones.
+type compoundPart struct {
+	keyword string
+	s       *Select
+}
+
+// add selects with the given keyword.
+func (c *Compound) add(keyword string, selects []*Select) *Compound {
+	for _, s := range selects {
+		c.parts = append(c.parts, compoundPart{keyword: keyword, s: s})
+	}
+	return c
+}
+
+// Union add selects combined with UNION.
+func (c *Compound) Union(selects ...*Select) *Compound {
+	return c.add(unionKeyword, selects)
+}
+
+// UnionAll add selects combined with UNION ALL.
+func (c *Compound) UnionAll(selects ...*Select) *Compound {
+	return c.add(unionAllKeyword, selects)
+}
+
+// Intersect add selects combined with INTERSECT.
+func (c *Compound) Intersect(selects ...*Select) *Compound {
+	return c.add(intersectKeyword, selects)
+}
+
+// Except add selects combined with EXCEPT.
+func (c *Compound) Except(selects ...*Select) *Compound {
+	return c.add(exceptKeyword, selects)
+}
+
+// Len returns the number of selects.
+func (c *Compound) Len() int {
+	if c.first == nil {
+		return 0
+	}
+	return len(c.parts) + 1
+}
+
+// String convert the compound to string.
+func (c *Compound) String() string {
+	return c.Render(DefaultDialect)
+}
+
+// Render convert the compound to string for the dialect d.
+func (c *Compound) Render(d Dialect) string {
+	return Rebind(d, c.render(d))
+}
+
+// render convert the compound to string with "?" placeholders.
+func (c *Compound) render(d Dialect) string {
+	if c.first == nil {
+		return ""
+	}
+
+	q := strings.Builder{}
+	q.WriteString(compoundSelect(d, c.first))
+	for _, p := range c.parts {
+		q.WriteString(p.keyword)
+		q.WriteString(compoundSelect(d, p.s))
+	}
+
+	// OrderBy clause
+	if c.OrderBy != nil && c.OrderBy.Len() > 0 {
+		q.WriteString(orderByKeyword)
+		q.WriteString(renderNested(d, c.OrderBy))
+	}
+
+	// Pagination
+	q.WriteString(d.Limit(c.Limit, c.Offset))
+
+	return q.String()
+}
+
+// Args compute the arguments of the compound in order.
+func (c *Compound) Args() (out []any) {
+	if c.first == nil {
+		return
+	}
+
+	out = append(out, c.first.Args()...)
+	for _, p := range c.parts {
+		out = append(out, p.s.Args()...)
+	}
+	return
+}
+
+// compoundSelect render a select of the compound, inside parenthesis
+// when it has its own ORDER BY or LIMIT clause.
+// SQLite does not support parenthesized selects, they are wrapped into a derived table.
+func compoundSelect(d Dialect, s *Select) string {
+	str := strings.TrimSpace(s.render(d))
+	if (s.OrderBy != nil && s.OrderBy.Len() > 0) || s.Limit > 0 {
+		if isDialect(d, SQLite) {
+			return "SELECT * FROM (" + str + ")"
+		}
+		return "(" + str + ")"
+	}
+	return str
+}
@@ -0,0 +1,42 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompound(t *testing.T) {
+	s1 := &Select{Table: "articles_2018", Columns: ParseColumns("id"), Where: ParseWhere("author_id = ?", 1)}
+	s2 := &Select{Table: "articles_2019", Columns: ParseColumns("id"), Where: ParseWhere("author_id = ?", 2)}
+	s3 := &Select{Table: "articles_2020", Columns: ParseColumns("id"), Where: ParseWhere("author_id = ?", 3), Limit: 5}
+
+	c := NewUnion(s1, s2)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, "SELECT id FROM articles_2018 WHERE author_id = ? UNION SELECT id FROM articles_2019 WHERE author_id = ?", c.String())
+	assert.Equal(t, []any{1, 2}, c.Args())
+
+	c.UnionAll(s3)
+	c.OrderBy.Add("id DESC")
+	c.Limit = 10
+
+	assert.Equal(t, "SELECT id FROM articles_2018 WHERE author_id = $1 UNION SELECT id FROM articles_2019 WHERE author_id = $2 UNION ALL (SELECT id FROM articles_2020 WHERE author_id = $3 LIMIT 5 OFFSET 0) ORDER BY id DESC LIMIT 10 OFFSET 0 ", c.Render(PostgreSQL))
+	assert.Equal(t, []any{1, 2, 3}, c.Args())
+
+	{
+		c := NewCompound(s1).Intersect(s2).Except(s3)
+		assert.Equal(t, "SELECT id FROM articles_2018 WHERE author_id = ? INTERSECT SELECT id FROM articles_2019 WHERE author_id = ? EXCEPT (SELECT id FROM articles_2020 WHERE author_id = ? LIMIT 5 OFFSET 0)", c.String())
+	}
+
+	// SQLite
+	{
+		c := NewUnionAll(s1, s3)
+		assert.Equal(t, "SELECT id FROM articles_2018 WHERE author_id = ? UNION ALL SELECT * FROM (SELECT id FROM articles_2020 WHERE author_id = ? LIMIT 5 OFFSET 0)", c.Render(SQLite))
+		assert.Equal(t, "SELECT \"id\" FROM \"articles_2018\" WHERE author_id = ? UNION ALL SELECT * FROM (SELECT \"id\" FROM \"articles_2020\" WHERE author_id = ? LIMIT 5 OFFSET 0)", c.Render(Quoted(SQLite)))
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	betweenKeyword   = " BETWEEN ? AND ?"
+	likeKeyword      = " LIKE ?"
+	isNullKeyword    = " IS NULL"
+	isNotNullKeyword = " IS NOT NULL"
+	notKeyword       = "NOT "
+)
+
+// Cond is a typed condition usable by Where, On and Having.
+type Cond interface {
+	// String convert the condition to string.
+	String() string
+
+	// Args return the arguments of the condition.
+	Args() []any
+
+	render(d Dialect) string
+}
+
+// Eq create a new condition "col = v", or "col IS NULL" when v is nil (or a nil pointer).
+// v can be a Stmt to compare col to a scalar subquery.
+func Eq(col string, v any) Cond {
+	if isNil(v) {
+		return IsNull(col)
+	}
+	return &compareCond{col: col, op: "=", v: v}
+}
+
+// NotEq create a new condition "col <> v", or "col IS NOT NULL" when v is nil (or a nil pointer).
+func NotEq(col string, v any) Cond {
+	if isNil(v) {
+		return IsNotNull(col)
+	}
+	return &compareCond{col: col, op: "<>", v: v}
+}
+
+// Gt create a new condition "col > v".
+func Gt(col string, v any) Cond {
+	return &compareCond{col: col, op: ">", v: v}
+}
+
+// Gte create a new condition "col >= v".
+func Gte(col string, v any) Cond {
+	return &compareCond{col: col, op: ">=", v: v}
+}
+
+// Lt create a new condition "col < v".
+func Lt(col string, v any) Cond {
+	return &compareCond{col: col, op: "<", v: v}
+}
+
+// Lte create a new condition "col <= v".
+func Lte(col string, v any) Cond {
+	return &compareCond{col: col, op: "<=", v: v}
+}
+
+// Between create a new condition "col BETWEEN min AND max".
+func Between(col string, min, max any) Cond {
+	return &rawCond{col: col, keyword: betweenKeyword, args: []any{min, max}}
+}
+
+// Like create a new condition "col LIKE pattern".
+func Like(col string, pattern string) Cond {
+	return &rawCond{col: col, keyword: likeKeyword, args: []any{pattern}}
+}
+
+// IsNull create a new condition "col IS NULL".
+func IsNull(col string) Cond {
+	return &rawCond{col: col, keyword: isNullKeyword}
+}
+
+// IsNotNull create a new condition "col IS NOT NULL".
+func IsNotNull(col string) Cond {
+	return &rawCond{col: col, keyword: isNotNullKeyword}
+}
+
+// In create a new condition "col IN (values)".
+// values can be a single Slicer, slice or array (expanded), or a single Stmt (subquery).
+// An empty list of values is always false.
+func In(col string, values ...any) Cond {
+	return &inCond{col: col, keyword: inKeyword, values: values}
+}
+
+// NotIn create a new condition "col NOT IN (values)".
+// An empty list of values is always true.
+func NotIn(col string, values ...any) Cond {
+	return &inCond{col: col, keyword: notInKeyword, values: values, not: true}
+}
+
+// Not create a new condition "NOT (c)".
+func Not(c Cond) Cond {
+	return &notCond{c: c}
+}
+
+// AnyOf create a new condition where at least one of conds is true.
+func AnyOf(conds ...Cond) Cond {
+	return &groupCond{keyword: orKeyword, conds: conds}
+}
+
+// AllOf create a new condition where all the conds are true.
+func AllOf(conds ...Cond) Cond {
+	return &groupCond{keyword: andKeyword, conds: conds}
+}
+
+// isNil says if v is nil or a nil pointer, sent as NULL to the driver.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// isEmpty says if the condition c renders nothing.
+func isEmpty(c Cond) bool {
+	switch c := c.(type) {
+	case *rawExpr:
+		return strings.TrimSpace(c.str) == ""
+	case *groupExpr:
+		return c.w.Len() == 0
+	case *notCond:
+		return isEmpty(c.c)
+	case *groupCond:
+		for _, cond := range c.conds {
+			if !isEmpty(cond) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compareCond is a comparison between a column and a value or a subquery.
+type compareCond struct {
+	col string
+	op  string
+	v   any
+}
+
+func (c *compareCond) String() string {
+	return c.render(DefaultDialect)
+}
+
+func (c *compareCond) render(d Dialect) string {
+	if s, ok := c.v.(Stmt); ok {
+		return fmt.Sprintf("%s %s (%s)", column(d, c.col), c.op, renderNested(d, s))
+	}
+	return fmt.Sprintf("%s %s ?", column(d, c.col), c.op)
+}
+
+func (c *compareCond) Args() []any {
+	if s, ok := c.v.(Stmt); ok {
+		return s.Args()
+	}
+	return []any{c.v}
+}
+
+// rawCond is a column followed by a keyword.
+type rawCond struct {
+	col     string
+	keyword string
+	args    []any
+}
+
+func (c *rawCond) String() string {
+	return c.render(DefaultDialect)
+}
+
+func (c *rawCond) render(d Dialect) string {
+	return column(d, c.col) + c.keyword
+}
+
+func (c *rawCond) Args() []any {
+	return c.args
+}
+
+// inCond is the operator IN (or NOT IN) on a list of values or a subquery.
+type inCond struct {
+	col     string
+	keyword string
+	values  []any
+	not     bool
+}
+
+// subquery returns the subquery of the condition if any.
+func (c *inCond) subquery() (Stmt, bool) {
+	if len(c.values) == 1 {
+		s, ok := c.values[0].(Stmt)
+		return s, ok
+	}
+	return nil, false
+}
+
+// list returns the values of the condition.
+func (c *inCond) list() []any {
+	if len(c.values) == 1 {
+		switch v := c.values[0].(type) {
+		case Slicer:
+			return v.S()
+		case []byte:
+		case []any:
+			return v
+		default:
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+				out := make([]any, rv.Len())
+				for i := range out {
+					out[i] = rv.Index(i).Interface()
+				}
+				return out
+			}
+		}
+	}
+	return c.values
+}
+
+func (c *inCond) String() string {
+	return c.render(DefaultDialect)
+}
+
+func (c *inCond) render(d Dialect) string {
+	if s, ok := c.subquery(); ok {
+		return fmt.Sprintf("%s%s(%s)", column(d, c.col), c.keyword, renderNested(d, s))
+	}
+
+	n := len(c.list())
+	if n == 0 {
+		if c.not {
+			return "1 = 1"
+		}
+		return "1 = 0"
+	}
+	return fmt.Sprintf("%s%s(%s)", column(d, c.col), c.keyword, strings.TrimRight(strings.Repeat("?,", n), ","))
+}
+
+func (c *inCond) Args() []any {
+	if s, ok := c.subquery(); ok {
+		return s.Args()
+	}
+	return c.list()
+}
+
+// notCond is the negation of a condition.
+type notCond struct {
+	c Cond
+}
+
+func (c *notCond) String() string {
+	return c.render(DefaultDialect)
+}
+
+func (c *notCond) render(d Dialect) string {
+	if str := c.c.render(d); str != "" {
+		return fmt.Sprintf("%s(%s)", notKeyword, str)
+	}
+	return ""
+}
+
+func (c *notCond) Args() []any {
+	return c.c.Args()
+}
+
+// groupCond is a list of conditions combined inside parenthesis.
+type groupCond struct {
+	keyword string
+	conds   []Cond
+}
+
+func (c *groupCond) String() string {
+	return c.render(DefaultDialect)
+}
+
+func (c *groupCond) render(d Dialect) string {
+	str := strings.Builder{}
+	for _, cond := range c.conds {
+		expr := cond.render(d)
+		if expr == "" {
+			continue
+		}
+
+		if str.Len() > 0 {
+			str.WriteString(c.keyword)
+		}
+		str.WriteString(expr)
+	}
+
+	if str.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s)", str.String())
+}
+
+func (c *groupCond) Args() (out []any) {
+	for _, cond := range c.conds {
+		out = append(out, cond.Args()...)
+	}
+	return
+}
@@ -0,0 +1,83 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCond(t *testing.T) {
+	assert.Equal(t, "col1 = ?", Eq("col1", 1).String())
+	assert.Equal(t, []any{1}, Eq("col1", 1).Args())
+	assert.Equal(t, "col1 <> ?", NotEq("col1", 1).String())
+	assert.Equal(t, "col1 > ?", Gt("col1", 1).String())
+	assert.Equal(t, "col1 >= ?", Gte("col1", 1).String())
+	assert.Equal(t, "col1 < ?", Lt("col1", 1).String())
+	assert.Equal(t, "col1 <= ?", Lte("col1", 1).String())
+	assert.Equal(t, "col1 BETWEEN ? AND ?", Between("col1", 1, 5).String())
+	assert.Equal(t, []any{1, 5}, Between("col1", 1, 5).Args())
+	assert.Equal(t, "col1 LIKE ?", Like("col1", "%test%").String())
+	assert.Equal(t, "col1 IS NULL", IsNull("col1").String())
+	assert.Empty(t, IsNull("col1").Args())
+	assert.Equal(t, "col1 IS NOT NULL", IsNotNull("col1").String())
+	assert.Equal(t, "col1 IN (?,?,?)", In("col1", 1, 2, 3).String())
+	assert.Equal(t, []any{1, 2, 3}, In("col1", 1, 2, 3).Args())
+	assert.Equal(t, "1 = 0", In("col1").String())
+	assert.Equal(t, "1 = 1", NotIn("col1").String())
+	assert.Equal(t, "NOT (col1 = ?)", Not(Eq("col1", 1)).String())
+}
+
+func TestCondNull(t *testing.T) {
+	var ptr *int
+
+	assert.Equal(t, "col1 IS NULL", Eq("col1", nil).String())
+	assert.Empty(t, Eq("col1", nil).Args())
+	assert.Equal(t, "col1 IS NULL", Eq("col1", ptr).String())
+	assert.Equal(t, "col1 IS NOT NULL", NotEq("col1", nil).String())
+}
+
+func TestCondInSlice(t *testing.T) {
+	assert.Equal(t, "id IN (?,?,?)", In("id", []int{1, 2, 3}).String())
+	assert.Equal(t, []any{1, 2, 3}, In("id", []int{1, 2, 3}).Args())
+	assert.Equal(t, []any{"a", "b"}, In("id", [2]string{"a", "b"}).Args())
+	assert.Equal(t, []any{"a", 1}, NotIn("id", []any{"a", 1}).Args())
+	assert.Equal(t, "1 = 0", In("id", []int{}).String())
+	assert.Equal(t, "id IN (?)", In("id", []byte("raw")).String())
+}
+
+func TestCondSubquery(t *testing.T) {
+	sub := &Select{Table: "authors", Columns: ParseColumns("id"), Where: ParseWhere("active = ?", true)}
+
+	assert.Equal(t, "author_id IN (SELECT id FROM authors WHERE active = ?)", In("author_id", sub).String())
+	assert.Equal(t, []any{true}, In("author_id", sub).Args())
+	assert.Equal(t, "author_id = (SELECT id FROM authors WHERE active = ?)", Eq("author_id", sub).String())
+}
+
+func TestCondGroup(t *testing.T) {
+	c := AllOf(
+		Eq("col1", 1),
+		AnyOf(IsNull("col2"), Gt("col2", 2)),
+		AnyOf(),
+	)
+
+	assert.Equal(t, "(col1 = ? AND (col2 IS NULL OR col2 > ?))", c.String())
+	assert.Equal(t, []any{1, 2}, c.Args())
+	assert.Empty(t, AllOf().String())
+}
+
+func TestWhereCond(t *testing.T) {
+	s := Select{
+		Table:  "articles a",
+		Joins:  Joins{ParseJoin("authors b", NewOn().AndCond(Eq("b.active", true)).And("b.id = a.author_id"))},
+		Where:  NewWhere().And("a.status = ?", 1).AndCond(Like("a.title", "go%"), Not(In("a.id", 1, 2))).OrCond(IsNull("a.deleted_at")),
+		Having: NewHaving().AndCond(Gte("COUNT(*)", 2)),
+	}
+
+	assert.Equal(t, " SELECT * FROM articles a JOIN authors b ON b.active = $1 AND b.id = a.author_id WHERE a.status = $2 AND a.title LIKE $3 AND NOT (a.id IN ($4,$5)) OR a.deleted_at IS NULL HAVING COUNT(*) >= $6", s.Render(PostgreSQL))
+	assert.Equal(t, []any{true, 1, "go%", 1, 2, 2}, s.Args())
+}
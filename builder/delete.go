@@ -6,45 +6,115 @@
 package builder
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 )
 
 const (
 	deleteKeyword = "DELETE "
+	usingKeyword  = " USING "
 )
 
+// ErrMissingTable is returned by Check when the table of an update or a delete is missing.
+var ErrMissingTable = errors.New("builder: missing table")
+
 // NewDelete create a new delete.
 func NewDelete(t string) *Delete {
 	return &Delete{
+		With:  CTEs{},
 		Table: t,
+		Joins: Joins{},
 		Where: NewWhere(),
 	}
 }
 
 // Delete is the representation of an Delete statement.
 type Delete struct {
+	With  CTEs
 	Table string
+
+	// From is a source used by the conditions (USING on PostgreSQL,
+	// multi-table delete on MySQL), it is not supported by SQLite.
+	From Source
+
+	// Joins turns the delete into a multi-table delete (MySQL).
+	Joins Joins
 	Where Where
 }
 
 // String convert the delete into string.
 func (d *Delete) String() string {
+	return d.Render(DefaultDialect)
+}
+
+// Render convert the delete to string for the dialect dialect.
+func (d *Delete) Render(dialect Dialect) string {
+	return Rebind(dialect, d.render(dialect))
+}
+
+// render convert the delete to string with "?" placeholders.
+func (d *Delete) render(dialect Dialect) string {
 	q := strings.Builder{}
+	if d.With.Len() > 0 {
+		q.WriteString(d.With.render(dialect))
+		q.WriteRune(' ')
+	}
+
+	using := !d.From.IsZero() && !isDialect(dialect, MySQL)
+
 	q.WriteString(deleteKeyword)
+	if !using && (!d.From.IsZero() || d.Joins.Len() > 0) {
+		// Only the rows of the table (or its alias) are deleted.
+		if fields := strings.Fields(d.Table); len(fields) > 0 {
+			q.WriteString(ident(dialect, fields[len(fields)-1]))
+		}
+	}
+
 	q.WriteString(fromKeyword)
-	q.WriteString(d.Table)
+	q.WriteString(ident(dialect, d.Table))
+
+	if !d.From.IsZero() {
+		if using {
+			q.WriteString(usingKeyword)
+		} else {
+			q.WriteString(", ")
+		}
+		q.WriteString(d.From.render(dialect))
+	}
+
+	// Joins section
+	if d.Joins.Len() > 0 {
+		q.WriteString(d.Joins.render(dialect))
+	}
 
 	if d.Where != nil && d.Where.Len() > 0 {
 		q.WriteString(whereKeyword)
-		q.WriteString(d.Where.String())
+		q.WriteString(renderNested(dialect, d.Where))
 	}
 	return q.String()
 }
 
+// check returns an error if the delete can't be rendered for the dialect dialect.
+func (d *Delete) check(dialect Dialect) error {
+	switch {
+	case strings.TrimSpace(d.Table) == "":
+		return ErrMissingTable
+	case d.Joins.Len() > 0 && !isDialect(dialect, MySQL):
+		return fmt.Errorf("%w: DELETE with joins on %s", ErrUnsupported, dialect.Name())
+	case !d.From.IsZero() && isDialect(dialect, SQLite):
+		return fmt.Errorf("%w: DELETE with a source on %s", ErrUnsupported, dialect.Name())
+	}
+	return nil
+}
+
 // Args compute the arguments of the delete statement.
 func (d *Delete) Args() (out []any) {
+	out = append(out, d.With.Args()...)
+	out = append(out, d.From.Args()...)
+	out = append(out, d.Joins.Args()...)
 	if d.Where != nil {
-		out = d.Where.Args()
+		out = append(out, d.Where.Args()...)
 	}
 	return
 }
@@ -20,3 +20,16 @@ func TestDelete(t *testing.T) {
 	assert.Equal(t, "DELETE  FROM test WHERE col1 = ? AND col2 = ?", d.String())
 	assert.Equal(t, d.Args(), []any{1, "val"})
 }
+
+func TestDeleteCheck(t *testing.T) {
+	d := NewDelete("")
+	d.Joins.Add(ParseJoin("authors b", ParseOn("b.id = author_id")))
+
+	assert.NotPanics(t, func() { _ = d.String() })
+	assert.ErrorIs(t, Check(MySQL, d), ErrMissingTable)
+
+	d.Table = "articles a"
+	assert.NoError(t, Check(MySQL, d))
+	assert.ErrorIs(t, Check(PostgreSQL, d), ErrUnsupported)
+	assert.ErrorIs(t, Check(Quoted(SQLite), d), ErrUnsupported)
+}
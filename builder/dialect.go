@@ -0,0 +1,373 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// MySQL is the dialect of MySQL & MariaDB.
+	MySQL Dialect = mysqlDialect{}
+
+	// PostgreSQL is the dialect of PostgreSQL.
+	PostgreSQL Dialect = postgresDialect{}
+
+	// SQLite is the dialect of SQLite.
+	SQLite Dialect = sqliteDialect{}
+
+	// DefaultDialect is the dialect used by the String method of the statements.
+	DefaultDialect = MySQL
+
+	// ErrUnsupported is returned by Check when a statement can't be rendered for a dialect.
+	ErrUnsupported = errors.New("builder: statement not supported by the dialect")
+
+	// dm is the mutex that manage the dialects registry.
+	dm sync.RWMutex
+
+	// dialects registered by driver name.
+	dialects = map[string]Dialect{
+		"mysql":    MySQL,
+		"postgres": PostgreSQL,
+		"pgx":      PostgreSQL,
+		"pgsql":    PostgreSQL,
+		"sqlite3":  SQLite,
+		"sqlite":   SQLite,
+	}
+)
+
+// Dialect is the representation of the SQL syntax of an engine.
+type Dialect interface {
+	// Name returns the name of the dialect.
+	Name() string
+
+	// Placeholder returns the n-th placeholder (starting at 1).
+	Placeholder(n int) string
+
+	// Quote returns the quoted identifier.
+	Quote(ident string) string
+
+	// Insert returns the INSERT keyword.
+	Insert(ignore bool) string
+
+	// Upsert returns the clause used when a conflict occurs during an insert.
+	Upsert(conflict Keys, keys Keys, raw RawKeys, ignore bool) string
+
+	// Limit returns the pagination clause.
+	Limit(limit, offset uint64) string
+
+	// Lock returns the locking clause of a select.
+	Lock(l Lock) string
+
+	// MaxPlaceholders returns the maximum number of placeholders of a statement.
+	MaxPlaceholders() int
+}
+
+// Renderer is implemented by statements that can be rendered for a given Dialect.
+type Renderer interface {
+	Render(d Dialect) string
+}
+
+// RegisterDialect register the dialect d for the given driver name.
+func RegisterDialect(driver string, d Dialect) {
+	dm.Lock()
+	defer dm.Unlock()
+	dialects[driver] = d
+}
+
+// GetDialect returns the dialect of the given driver name or DefaultDialect.
+func GetDialect(driver string) Dialect {
+	dm.RLock()
+	defer dm.RUnlock()
+	if d, ok := dialects[driver]; ok {
+		return d
+	}
+	return DefaultDialect
+}
+
+// Render renders s with the dialect d.
+// Statements that do not implement Renderer are rebinded.
+func Render(d Dialect, s fmt.Stringer) string {
+	if r, ok := s.(Renderer); ok {
+		return r.Render(d)
+	}
+	return Rebind(d, s.String())
+}
+
+// Check returns an error if s can't be rendered for the dialect d.
+func Check(d Dialect, s Stmt) error {
+	if c, ok := s.(checker); ok {
+		return c.check(d)
+	}
+	return nil
+}
+
+// checker is implemented by the statements of the package that are not
+// supported by every dialect.
+type checker interface {
+	check(d Dialect) error
+}
+
+// isDialect says if d is the dialect other (quoted or not).
+func isDialect(d Dialect, other Dialect) bool {
+	return d.Name() == other.Name()
+}
+
+// renderer is implemented by the statements of the package to be nested
+// into another one before the placeholders are rebinded.
+type renderer interface {
+	render(d Dialect) string
+}
+
+// renderNested renders the nested statement s with "?" placeholders.
+func renderNested(d Dialect, s fmt.Stringer) string {
+	if r, ok := s.(renderer); ok {
+		return strings.TrimSpace(r.render(d))
+	}
+	return strings.TrimSpace(s.String())
+}
+
+// Rebind replaces the "?" placeholders of the query by the ones of the dialect d.
+// Placeholders inside quoted strings and identifiers are left untouched.
+func Rebind(d Dialect, query string) string {
+	if d.Placeholder(1) == "?" || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var (
+		out   strings.Builder
+		quote rune
+		n     int
+	)
+
+	out.Grow(len(query) + 8)
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+			out.WriteString(d.Placeholder(n))
+			continue
+		}
+		out.WriteRune(c)
+	}
+	return out.String()
+}
+
+// sortedRawKeys returns the keys of raw sorted.
+func sortedRawKeys(raw RawKeys) Keys {
+	keys := make(Keys, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// limit is the pagination clause shared by the dialects.
+func limit(limit, offset uint64) string {
+	if limit > 0 {
+		return fmt.Sprintf(" LIMIT %d OFFSET %d ", limit, offset)
+	}
+	return ""
+}
+
+// onConflict write the ON CONFLICT clause shared by PostgreSQL & SQLite.
+// DO UPDATE requires a conflict target, without one the conflicts are ignored (DO NOTHING).
+func onConflict(conflict Keys, keys Keys, raw RawKeys, ignore bool, excluded string) string {
+	update := len(keys) > 0 || len(raw) > 0
+	if !update && !ignore {
+		return ""
+	}
+
+	q := strings.Builder{}
+	q.WriteString(onConflictKeyword)
+	if len(conflict) > 0 {
+		q.WriteRune('(')
+		q.WriteString(strings.Join(conflict, ","))
+		q.WriteString(") ")
+	}
+
+	if !update {
+		q.WriteString(doNothingKeyword)
+		return q.String()
+	}
+
+	q.WriteString(doUpdateSetKeyword)
+	first := true
+	for _, k := range keys {
+		if !first {
+			q.WriteRune(',')
+		} else {
+			first = false
+		}
+
+		fmt.Fprintf(&q, "%s = %s.%s", k, excluded, k)
+	}
+
+	for _, k := range sortedRawKeys(raw) {
+		if !first {
+			q.WriteRune(',')
+		} else {
+			first = false
+		}
+
+		fmt.Fprintf(&q, "%s = %s", k, raw[k])
+	}
+	return q.String()
+}
+
+// mysqlDialect is the dialect of MySQL.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) Quote(ident string) string {
+	return quoteWith("`", ident)
+}
+
+func (mysqlDialect) Insert(ignore bool) string {
+	if ignore {
+		return insertKeyword + ignoreKeyword
+	}
+	return insertKeyword
+}
+
+func (mysqlDialect) Upsert(_ Keys, keys Keys, raw RawKeys, _ bool) string {
+	if len(keys) == 0 && len(raw) == 0 {
+		return ""
+	}
+
+	q := strings.Builder{}
+	q.WriteString(onDuplicateKeyUpdateKeyword)
+	first := true
+
+	for _, k := range keys {
+		if !first {
+			q.WriteRune(',')
+		} else {
+			first = false
+		}
+
+		fmt.Fprintf(&q, "%s = VALUES(%s)", k, k)
+	}
+
+	for _, k := range sortedRawKeys(raw) {
+		if !first {
+			q.WriteRune(',')
+		} else {
+			first = false
+		}
+
+		fmt.Fprintf(&q, "%s = %s", k, raw[k])
+	}
+	return q.String()
+}
+
+func (mysqlDialect) Limit(l, offset uint64) string {
+	return limit(l, offset)
+}
+
+func (mysqlDialect) Lock(l Lock) string {
+	return lock(l)
+}
+
+func (mysqlDialect) MaxPlaceholders() int {
+	return 65535
+}
+
+// postgresDialect is the dialect of PostgreSQL.
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgresDialect) Quote(ident string) string {
+	return quoteWith(`"`, ident)
+}
+
+func (postgresDialect) Insert(bool) string {
+	return insertKeyword
+}
+
+func (postgresDialect) Upsert(conflict Keys, keys Keys, raw RawKeys, ignore bool) string {
+	return onConflict(conflict, keys, raw, ignore, "EXCLUDED")
+}
+
+func (postgresDialect) Limit(l, offset uint64) string {
+	return limit(l, offset)
+}
+
+func (postgresDialect) Lock(l Lock) string {
+	if l.Mode == LockInShareMode {
+		l.Mode = LockForShare
+	}
+	return lock(l)
+}
+
+func (postgresDialect) MaxPlaceholders() int {
+	return 65535
+}
+
+// sqliteDialect is the dialect of SQLite.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (sqliteDialect) Quote(ident string) string {
+	return quoteWith(`"`, ident)
+}
+
+func (sqliteDialect) Insert(ignore bool) string {
+	if ignore {
+		return insertKeyword + orIgnoreKeyword
+	}
+	return insertKeyword
+}
+
+func (sqliteDialect) Upsert(conflict Keys, keys Keys, raw RawKeys, _ bool) string {
+	return onConflict(conflict, keys, raw, false, "excluded")
+}
+
+func (sqliteDialect) Limit(l, offset uint64) string {
+	return limit(l, offset)
+}
+
+// Lock returns nothing, SQLite locks the whole database during a transaction.
+func (sqliteDialect) Lock(Lock) string {
+	return ""
+}
+
+func (sqliteDialect) MaxPlaceholders() int {
+	return 999
+}
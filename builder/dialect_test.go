@@ -0,0 +1,60 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	assert.Equal(t, "col1 = ? AND col2 = ?", Rebind(MySQL, "col1 = ? AND col2 = ?"))
+	assert.Equal(t, "col1 = $1 AND col2 = $2", Rebind(PostgreSQL, "col1 = ? AND col2 = ?"))
+	assert.Equal(t, "col1 = '?' AND col2 = $1", Rebind(PostgreSQL, "col1 = '?' AND col2 = ?"))
+}
+
+func TestGetDialect(t *testing.T) {
+	assert.Equal(t, MySQL, GetDialect("mysql"))
+	assert.Equal(t, PostgreSQL, GetDialect("postgres"))
+	assert.Equal(t, SQLite, GetDialect("sqlite3"))
+	assert.Equal(t, DefaultDialect, GetDialect("unknown"))
+}
+
+func TestDialectSelect(t *testing.T) {
+	s := Select{
+		Table: "test",
+		Where: ParseWhere("col1 = ? AND col2 = ?", 1, "val"),
+		Limit: 10,
+	}
+
+	assert.Equal(t, " SELECT * FROM test WHERE col1 = ? AND col2 = ? LIMIT 10 OFFSET 0 ", s.Render(MySQL))
+	assert.Equal(t, " SELECT * FROM test WHERE col1 = $1 AND col2 = $2 LIMIT 10 OFFSET 0 ", s.Render(PostgreSQL))
+	assert.Equal(t, " SELECT * FROM test WHERE col1 = ? AND col2 = ? LIMIT 10 OFFSET 0 ", s.Render(SQLite))
+}
+
+func TestDialectInsert(t *testing.T) {
+	i := Insert{
+		Table: "test",
+		Values: H{
+			"col1": "val1",
+			"col2": "val2",
+		},
+		ConflictKeys: Keys{"col1"},
+		OnUpdateKeys: Keys{"col2"},
+	}
+
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES(?,?) ON DUPLICATE KEY UPDATE col2 = VALUES(col2)", i.Render(MySQL))
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES($1,$2) ON CONFLICT (col1) DO UPDATE SET col2 = EXCLUDED.col2", i.Render(PostgreSQL))
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES(?,?) ON CONFLICT (col1) DO UPDATE SET col2 = excluded.col2", i.Render(SQLite))
+
+	i.OnUpdateKeys = nil
+	i.IgnoreMode = true
+
+	assert.Equal(t, "INSERT IGNORE INTO test(col1,col2) VALUES(?,?)", i.Render(MySQL))
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES($1,$2) ON CONFLICT (col1) DO NOTHING", i.Render(PostgreSQL))
+	assert.Equal(t, "INSERT OR IGNORE INTO test(col1,col2) VALUES(?,?)", i.Render(SQLite))
+}
@@ -18,7 +18,8 @@ func NewGroupBy() GroupBy {
 // This function should be called to initiate the GroupBy field.
 func ParseGroupBy(cols ...string) GroupBy {
 	out := &groupBy{}
-	return out.Add(cols...)
+	out.Add(cols...)
+	return out
 }
 
 // GroupBy is the representation of the GROUP BY clause.
@@ -30,7 +31,14 @@ type groupBy struct {
 	columns
 }
 
-// String return the natural string of Columns with "*".
+// String return the natural string of Columns without "*".
 func (gb *groupBy) String() string {
-	return gb.str.String()
+	return gb.render(DefaultDialect)
+}
+
+func (gb *groupBy) render(d Dialect) string {
+	if len(gb.list) == 0 {
+		return ""
+	}
+	return gb.columns.render(d)
 }
@@ -0,0 +1,158 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidIdentifier is returned by Validate when an identifier is not valid.
+	ErrInvalidIdentifier = errors.New("builder: invalid identifier")
+
+	// identRegexp match an unquoted identifier.
+	identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+)
+
+// Quoted returns the dialect d which quotes the identifiers of the statements.
+// Tables, keys & order by entries are always quoted, columns are quoted only
+// when they are not expressions.
+func Quoted(d Dialect) Dialect {
+	if q, ok := d.(*quotedDialect); ok {
+		return q
+	}
+	return &quotedDialect{Dialect: d}
+}
+
+// Validate renders s with the dialect d and returns an error if a table,
+// a key or an order by entry is not a valid identifier.
+func Validate(d Dialect, s Stmt) error {
+	if q, ok := d.(*quotedDialect); ok {
+		d = q.Dialect
+	}
+
+	q := &quotedDialect{Dialect: d, strict: true}
+	_ = Render(q, s)
+	if len(q.errs) > 0 {
+		return q.errs[0]
+	}
+	return nil
+}
+
+// quotedDialect is a dialect which quotes the identifiers.
+type quotedDialect struct {
+	Dialect
+	strict bool
+	errs   []error
+}
+
+// quote quotes name or returns it untouched if it is not an identifier.
+func (d *quotedDialect) quote(name string, strict bool) string {
+	out, ok := quoteName(d.Dialect, name)
+	if !ok && strict && d.strict {
+		d.errs = append(d.errs, fmt.Errorf("%w: %q", ErrInvalidIdentifier, name))
+	}
+	return out
+}
+
+// ident renders name as a table, a key or an alias.
+func ident(d Dialect, name string) string {
+	if q, ok := d.(*quotedDialect); ok {
+		return q.quote(name, true)
+	}
+	return name
+}
+
+// identKeys renders keys as identifiers.
+func identKeys(d Dialect, keys Keys) Keys {
+	if _, ok := d.(*quotedDialect); !ok || len(keys) == 0 {
+		return keys
+	}
+
+	out := make(Keys, len(keys))
+	for i, k := range keys {
+		out[i] = ident(d, k)
+	}
+	return out
+}
+
+// column renders name as an identifier when it is one, else as an expression.
+func column(d Dialect, name string) string {
+	if q, ok := d.(*quotedDialect); ok {
+		return q.quote(name, false)
+	}
+	return name
+}
+
+// quoteName quotes the name (with an optional alias) and says if it is valid.
+// Supported forms are "col", "table.col", "schema.table.col", "table.*"
+// followed by an optional alias ("table t" or "table AS t").
+func quoteName(d Dialect, name string) (string, bool) {
+	var (
+		fields = strings.Fields(name)
+		alias  string
+		as     string
+	)
+
+	switch {
+	case len(fields) == 1:
+	case len(fields) == 2:
+		as, alias = " ", fields[1]
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		as, alias = " "+fields[1]+" ", fields[2]
+	default:
+		return name, false
+	}
+
+	path, ok := quotePath(d, fields[0])
+	if !ok {
+		return name, false
+	}
+
+	if alias != "" {
+		if !identRegexp.MatchString(alias) {
+			return name, false
+		}
+		path += as + d.Quote(alias)
+	}
+	return path, true
+}
+
+// quotePath quotes each part of a dotted identifier.
+func quotePath(d Dialect, path string) (string, bool) {
+	parts := strings.Split(path, ".")
+	for i, p := range parts {
+		switch {
+		case p == "*" && i == len(parts)-1:
+		case isQuoted(p):
+		case identRegexp.MatchString(p):
+			parts[i] = d.Quote(p)
+		default:
+			return path, false
+		}
+	}
+	return strings.Join(parts, "."), true
+}
+
+// isQuoted says if the identifier is already quoted, the quote character
+// is only allowed inside as a doubled escape.
+func isQuoted(p string) bool {
+	n := len(p)
+	if n < 3 || p[0] != p[n-1] || (p[0] != '`' && p[0] != '"') {
+		return false
+	}
+
+	c := p[:1]
+	return !strings.Contains(strings.ReplaceAll(p[1:n-1], c+c, ""), c)
+}
+
+// quoteWith quotes ident with the quote character c.
+func quoteWith(c string, ident string) string {
+	return c + strings.ReplaceAll(ident, c, c+c) + c
+}
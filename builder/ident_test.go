@@ -0,0 +1,112 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteName(t *testing.T) {
+	for in, out := range map[string]string{
+		"col":               "`col`",
+		"order":             "`order`",
+		"t.col":             "`t`.`col`",
+		"schema.t.col":      "`schema`.`t`.`col`",
+		"t.*":               "`t`.*",
+		"*":                 "*",
+		"articles a":        "`articles` `a`",
+		"articles AS a":     "`articles` AS `a`",
+		"`already`.col":     "`already`.`col`",
+		"we`ird":            "we`ird",
+		"COUNT(*) AS total": "COUNT(*) AS total",
+	} {
+		quoted, _ := quoteName(MySQL, in)
+		assert.Equal(t, out, quoted, in)
+	}
+
+	quoted, ok := quoteName(PostgreSQL, "public.users u")
+	assert.True(t, ok)
+	assert.Equal(t, `"public"."users" "u"`, quoted)
+
+	_, ok = quoteName(PostgreSQL, "id; DROP TABLE users")
+	assert.False(t, ok)
+}
+
+func TestQuoted(t *testing.T) {
+	s := Select{
+		Table:   "public.articles a",
+		Columns: ParseColumns("a.id", "a.order", "COUNT(*) AS total"),
+		Joins:   Joins{ParseJoin("authors b", ParseOn("b.id = a.author_id"))},
+		Where:   NewWhere().AndCond(Eq("a.status", 1)).AndIn("a.type", slicer{1, 2}),
+		GroupBy: ParseGroupBy("a.id"),
+		OrderBy: ParseOrderBy("a.order desc, a.id"),
+	}
+
+	assert.Equal(t, ` SELECT "a"."id","a"."order",COUNT(*) AS total FROM "public"."articles" "a" JOIN "authors" "b" ON b.id = a.author_id WHERE "a"."status" = $1 AND "a"."type" IN ($2,$3) GROUP BY "a"."id" ORDER BY "a"."order" DESC,"a"."id"`, s.Render(Quoted(PostgreSQL)))
+
+	i := Insert{
+		Table:           "test",
+		Values:          H{"key": 1, "order": 2},
+		OnUpdateKeys:    Keys{"order"},
+		OnUpdateRawKeys: RawKeys{"key": "`key` + 1"},
+	}
+	assert.Equal(t, "INSERT INTO `test`(`key`,`order`) VALUES(?,?) ON DUPLICATE KEY UPDATE `order` = VALUES(`order`),`key` = `key` + 1", i.Render(Quoted(MySQL)))
+
+	u := Update{
+		Table:  "test",
+		Values: H{"order": 1},
+		Binds:  Binds{"count": "count + 1"},
+		Where:  NewWhere().AndCond(Eq("id", 1)),
+	}
+	assert.Equal(t, "UPDATE `test` SET `order` = ?,`count` = count + 1 WHERE `id` = ?", u.Render(Quoted(MySQL)))
+}
+
+func TestValidate(t *testing.T) {
+	s := Select{
+		Table:   "articles",
+		Columns: ParseColumns("COUNT(*) AS total"),
+		OrderBy: ParseOrderBy("created_at DESC"),
+	}
+	assert.NoError(t, Validate(MySQL, &s))
+
+	s.OrderBy = ParseOrderBy("(SELECT SLEEP(10))")
+	err := Validate(MySQL, &s)
+	assert.True(t, errors.Is(err, ErrInvalidIdentifier))
+
+	s.OrderBy = ParseOrderBy("`id`,(SELECT(SLEEP(5))),`x`")
+	assert.ErrorIs(t, Validate(MySQL, &s), ErrInvalidIdentifier)
+
+	s.OrderBy = ParseOrderBy(`"id"",(SELECT 1),""x"`)
+	assert.ErrorIs(t, Validate(PostgreSQL, &s), ErrInvalidIdentifier)
+
+	s.OrderBy = ParseOrderBy("`a``b`.`id` DESC")
+	assert.NoError(t, Validate(MySQL, &s))
+	assert.Equal(t, " SELECT COUNT(*) AS total FROM `articles` ORDER BY `a``b`.`id` DESC", s.Render(Quoted(MySQL)))
+
+	i := Insert{
+		Table:  "test",
+		Values: H{"col1 = 1; --": 1},
+	}
+	assert.Error(t, Validate(Quoted(PostgreSQL), &i))
+}
+
+// slicer is a Slicer of int.
+type slicer []int
+
+func (s slicer) Len() int {
+	return len(s)
+}
+
+func (s slicer) S() []any {
+	out := make([]any, len(s))
+	for i := range s {
+		out[i] = s[i]
+	}
+	return out
+}
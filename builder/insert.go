@@ -13,9 +13,13 @@ import (
 const (
 	insertKeyword               = "INSERT "
 	ignoreKeyword               = "IGNORE "
+	orIgnoreKeyword             = "OR IGNORE "
 	intoKeyword                 = "INTO "
 	valuesKeyword               = " VALUES"
 	onDuplicateKeyUpdateKeyword = " ON DUPLICATE KEY UPDATE "
+	onConflictKeyword           = " ON CONFLICT "
+	doNothingKeyword            = "DO NOTHING"
+	doUpdateSetKeyword          = "DO UPDATE SET "
 )
 
 // NewInsert create a new insert.
@@ -24,7 +28,9 @@ func NewInsert(t string) *Insert {
 		Table:           t,
 		Select:          Select{},
 		Values:          H{},
-		keys:            Keys{},
+		Rows:            []H{},
+		Columns:         Keys{},
+		ConflictKeys:    Keys{},
 		OnUpdateKeys:    Keys{},
 		OnUpdateRawKeys: RawKeys{},
 	}
@@ -32,68 +38,215 @@ func NewInsert(t string) *Insert {
 
 // Insert is the representation of an Insert statement.
 type Insert struct {
-	Table           string
-	Select          Select
-	IgnoreMode      bool
-	Values          H
-	keys            Keys
+	Table      string
+	Select     Select
+	IgnoreMode bool
+	Values     H
+
+	// Columns is the list of columns filled by the Select (INSERT ... SELECT).
+	Columns Keys
+
+	// Rows is the list of values of a multi-row insert.
+	// When set, Values is ignored and missing keys are inserted as NULL.
+	Rows []H
+
+	// ConflictKeys is the conflict target of the upsert (PostgreSQL & SQLite).
+	// It is required by OnUpdateKeys & OnUpdateRawKeys, Check returns ErrUnsupported without it.
+	ConflictKeys    Keys
 	OnUpdateKeys    Keys
 	OnUpdateRawKeys RawKeys
+
+	// keys are the columns of the rows the insert is a chunk of.
+	keys Keys
+}
+
+// hasSelect says if the insert is filled by the Select.
+func (i *Insert) hasSelect() bool {
+	return i.Select.Table != "" || !i.Select.From.IsZero()
+}
+
+// AddRow add rows to the multi-row insert.
+func (i *Insert) AddRow(rows ...H) *Insert {
+	i.Rows = append(i.Rows, rows...)
+	return i
+}
+
+// Len returns the number of rows inserted.
+func (i *Insert) Len() int {
+	if len(i.Rows) > 0 {
+		return len(i.Rows)
+	}
+	return 1
+}
+
+// Keys returns the columns of the insert (sorted unless filled by the Select).
+func (i *Insert) Keys() Keys {
+	if i.hasSelect() {
+		return i.Columns
+	}
+
+	if len(i.Rows) == 0 {
+		return i.Values.Keys()
+	}
+
+	if i.keys != nil {
+		return i.keys
+	}
+
+	union := H{}
+	for _, row := range i.Rows {
+		for k := range row {
+			union[k] = nil
+		}
+	}
+	return union.Keys()
 }
 
 // String convert the insert to string.
 func (i *Insert) String() string {
-	q := strings.Builder{}
-	q.WriteString(insertKeyword)
+	return i.Render(DefaultDialect)
+}
 
-	if i.IgnoreMode {
-		q.WriteString(ignoreKeyword)
-	}
+// Render convert the insert to string for the dialect d.
+func (i *Insert) Render(d Dialect) string {
+	return Rebind(d, i.render(d))
+}
+
+// render convert the insert to string with "?" placeholders.
+func (i *Insert) render(d Dialect) string {
+	q := strings.Builder{}
+	q.WriteString(d.Insert(i.IgnoreMode))
 
-	keys := i.Values.Keys()
+	keys := i.Keys()
 	n := len(keys)
 
 	q.WriteString(intoKeyword)
-	q.WriteString(i.Table)
-	q.WriteRune('(')
-	q.WriteString(strings.Join(keys, ","))
-	q.WriteRune(')')
-	q.WriteString(valuesKeyword)
-	q.WriteRune('(')
-	q.WriteString(strings.Repeat("?,", n)[:(n*2)-1])
-	q.WriteRune(')')
-
-	if len(i.OnUpdateKeys) > 0 || len(i.OnUpdateRawKeys) > 0 {
-		q.WriteString(onDuplicateKeyUpdateKeyword)
-		first := true
-
-		for _, k := range i.OnUpdateKeys {
-			if !first {
-				q.WriteRune(',')
-			} else {
-				first = false
-			}
+	q.WriteString(ident(d, i.Table))
+	if n > 0 {
+		q.WriteRune('(')
+		q.WriteString(strings.Join(identKeys(d, keys), ","))
+		q.WriteRune(')')
+	}
 
-			fmt.Fprintf(&q, "%s = VALUES(%s)", k, k)
+	raw := RawKeys{}
+	for k, exp := range i.OnUpdateRawKeys {
+		raw[ident(d, k)] = exp
+	}
+	upsert := d.Upsert(identKeys(d, i.ConflictKeys), identKeys(d, i.OnUpdateKeys), raw, i.IgnoreMode)
+
+	if i.hasSelect() {
+		// the CTEs of the select are kept after INSERT INTO, the only
+		// position supported by MySQL, PostgreSQL & SQLite.
+		s := i.Select
+		if strings.HasPrefix(upsert, onConflictKeyword) && (s.Where == nil || s.Where.Len() == 0) {
+			// SQLite parses ON CONFLICT as a join constraint without WHERE.
+			s.Where = ParseWhere("true")
 		}
 
-		for key, exp := range i.OnUpdateRawKeys {
-			if !first {
+		q.WriteRune(' ')
+		q.WriteString(renderNested(d, &s))
+	} else {
+		q.WriteString(valuesKeyword)
+
+		row := "(" + strings.Repeat("?,", n)[:(n*2)-1] + ")"
+		for r := 0; r < i.Len(); r++ {
+			if r > 0 {
 				q.WriteRune(',')
-			} else {
-				first = false
 			}
-
-			fmt.Fprintf(&q, "%s = %s", key, exp)
+			q.WriteString(row)
 		}
 	}
+
+	q.WriteString(upsert)
 	return q.String()
 }
 
+// check returns an error if the insert is not supported by the dialect d.
+func (i *Insert) check(d Dialect) error {
+	update := len(i.OnUpdateKeys) > 0 || len(i.OnUpdateRawKeys) > 0
+	if update && len(i.ConflictKeys) == 0 && strings.HasPrefix(d.Upsert(nil, i.OnUpdateKeys, i.OnUpdateRawKeys, false), onConflictKeyword) {
+		return fmt.Errorf("%w: upsert without conflict keys on %s", ErrUnsupported, d.Name())
+	}
+	return nil
+}
+
 // Args compute the arguments of the insert statement.
 func (i *Insert) Args() (out []any) {
-	for _, k := range i.Values.Keys() {
-		out = append(out, i.Values[k])
+	if i.hasSelect() {
+		return i.Select.Args()
+	}
+
+	if len(i.Rows) == 0 {
+		for _, k := range i.Values.Keys() {
+			out = append(out, i.Values[k])
+		}
+		return
+	}
+
+	keys := i.Keys()
+	out = make([]any, 0, len(keys)*len(i.Rows))
+	for _, row := range i.Rows {
+		for _, k := range keys {
+			out = append(out, row[k])
+		}
 	}
 	return
 }
+
+// Chunks split a multi-row insert into inserts where each one has at most
+// maxArgs arguments and an estimated size of maxBytes. Zero means no limit.
+func (i *Insert) Chunks(maxArgs, maxBytes int) []*Insert {
+	if i.hasSelect() || len(i.Rows) <= 1 {
+		return []*Insert{i}
+	}
+
+	var (
+		keys   = i.Keys()
+		base   = len(i.chunk(keys, 0, 1).render(MySQL))
+		out    = []*Insert{}
+		start  = 0
+		size   = base
+		nbArgs = 0
+	)
+
+	for r, row := range i.Rows {
+		rowSize := len(keys)*2 + 2
+		for _, k := range keys {
+			rowSize += argSize(row[k])
+		}
+
+		full := (maxArgs > 0 && nbArgs+len(keys) > maxArgs) ||
+			(maxBytes > 0 && size+rowSize > maxBytes)
+
+		if full && r > start {
+			out = append(out, i.chunk(keys, start, r))
+			start, size, nbArgs = r, base, 0
+		}
+
+		size += rowSize
+		nbArgs += len(keys)
+	}
+	return append(out, i.chunk(keys, start, len(i.Rows)))
+}
+
+// chunk copy the insert with the rows between start & end, the columns
+// are the keys of all the rows so a missing key is inserted as NULL in every chunk.
+func (i *Insert) chunk(keys Keys, start, end int) *Insert {
+	c := *i
+	c.Rows = i.Rows[start:end]
+	c.keys = keys
+	return &c
+}
+
+// argSize estimate the size of the argument v once sent to the server.
+func argSize(v any) int {
+	switch v := v.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case fmt.Stringer:
+		return len(v.String())
+	}
+	return 8
+}
@@ -33,3 +33,118 @@ func TestInsert(t *testing.T) {
 
 	assert.Equal(t, "INSERT IGNORE INTO test(col1,col2,col3) VALUES(?,?,?) ON DUPLICATE KEY UPDATE col1 = VALUES(col1),col3 = VALUES(col3)", i.String())
 }
+
+func TestInsertRows(t *testing.T) {
+	i := NewInsert("test")
+	i.AddRow(
+		H{"col1": 1, "col2": "a"},
+		H{"col1": 2, "col2": "b"},
+		H{"col1": 3},
+	)
+
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES(?,?),(?,?),(?,?)", i.String())
+	assert.Equal(t, []any{1, "a", 2, "b", 3, nil}, i.Args())
+
+	i.OnUpdateKeys = Keys{"col2"}
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES(?,?),(?,?),(?,?) ON DUPLICATE KEY UPDATE col2 = VALUES(col2)", i.String())
+	assert.NoError(t, Check(MySQL, i))
+	assert.ErrorIs(t, Check(PostgreSQL, i), ErrUnsupported)
+	assert.ErrorIs(t, Check(Quoted(SQLite), i), ErrUnsupported)
+
+	i.OnUpdateKeys = Keys{}
+	i.IgnoreMode = true
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES($1,$2),($3,$4),($5,$6) ON CONFLICT DO NOTHING", i.Render(PostgreSQL))
+	assert.NoError(t, Check(PostgreSQL, i))
+
+	i.IgnoreMode = false
+	i.OnUpdateKeys = Keys{"col2"}
+
+	i.ConflictKeys = Keys{"col1"}
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES($1,$2),($3,$4),($5,$6) ON CONFLICT (col1) DO UPDATE SET col2 = EXCLUDED.col2", i.Render(PostgreSQL))
+	assert.NoError(t, Check(PostgreSQL, i))
+}
+
+func TestInsertChunks(t *testing.T) {
+	i := NewInsert("test")
+	for n := 0; n < 5; n++ {
+		i.AddRow(H{"col1": n, "col2": "value"})
+	}
+
+	chunks := i.Chunks(4, 0)
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, "INSERT INTO test(col1,col2) VALUES(?,?),(?,?)", chunks[0].String())
+	assert.Equal(t, []any{0, "value", 1, "value"}, chunks[0].Args())
+	assert.Equal(t, []any{4, "value"}, chunks[2].Args())
+
+	assert.Len(t, i.Chunks(0, 0), 1)
+	assert.Len(t, i.Chunks(0, 1), 5)
+}
+
+func TestInsertChunksKeys(t *testing.T) {
+	i := NewInsert("t")
+	i.AddRow(H{"a": 1}, H{"a": 2, "b": 3})
+	assert.Equal(t, "INSERT INTO t(a,b) VALUES(?,?),(?,?)", i.String())
+
+	chunks := i.Chunks(2, 0)
+	if assert.Len(t, chunks, 2) {
+		assert.Equal(t, "INSERT INTO t(a,b) VALUES(?,?)", chunks[0].String())
+		assert.Equal(t, []any{1, nil}, chunks[0].Args())
+		assert.Equal(t, "INSERT INTO t(a,b) VALUES(?,?)", chunks[1].String())
+		assert.Equal(t, []any{2, 3}, chunks[1].Args())
+	}
+}
+
+func TestInsertSelect(t *testing.T) {
+	i := NewInsert("archive")
+	i.Columns = Keys{"id", "title"}
+	i.Select = Select{
+		Table:   "articles a",
+		Columns: ParseColumns("a.id", "a.title"),
+		Joins:   Joins{ParseJoin("authors b", ParseOn("b.id = a.author_id AND b.nickname = ?", "kovacou"))},
+		Where:   ParseWhere("a.created_at < ?", "2019-01-01"),
+	}
+
+	assert.Equal(t, "INSERT INTO archive(id,title) SELECT a.id,a.title FROM articles a JOIN authors b ON b.id = a.author_id AND b.nickname = ? WHERE a.created_at < ?", i.String())
+	assert.Equal(t, []any{"kovacou", "2019-01-01"}, i.Args())
+
+	i.IgnoreMode = true
+	i.ConflictKeys = Keys{"id"}
+	i.OnUpdateKeys = Keys{"title"}
+
+	assert.Equal(t, "INSERT IGNORE INTO archive(id,title) SELECT a.id,a.title FROM articles a JOIN authors b ON b.id = a.author_id AND b.nickname = ? WHERE a.created_at < ? ON DUPLICATE KEY UPDATE title = VALUES(title)", i.String())
+	assert.Equal(t, "INSERT INTO archive(id,title) SELECT a.id,a.title FROM articles a JOIN authors b ON b.id = a.author_id AND b.nickname = $1 WHERE a.created_at < $2 ON CONFLICT (id) DO UPDATE SET title = EXCLUDED.title", i.Render(PostgreSQL))
+	assert.Len(t, i.Chunks(1, 1), 1)
+}
+
+func TestInsertSelectWith(t *testing.T) {
+	i := NewInsert("a")
+	i.Select = Select{
+		With:    CTEs{NewCTE("c", &Select{Table: "d", Where: ParseWhere("e = ?", 1)})},
+		Table:   "c",
+		Columns: ParseColumns("x"),
+	}
+
+	assert.Equal(t, "INSERT INTO a WITH c AS (SELECT * FROM d WHERE e = ?) SELECT x FROM c", i.String())
+	assert.Equal(t, []any{1}, i.Args())
+
+	i.Columns = Keys{"x"}
+	assert.Equal(t, "INSERT INTO a(x) WITH c AS (SELECT * FROM d WHERE e = $1) SELECT x FROM c", i.Render(PostgreSQL))
+}
+
+func TestInsertSelectSQLiteUpsert(t *testing.T) {
+	i := NewInsert("archive")
+	i.Columns = Keys{"id", "title"}
+	i.Select = Select{
+		Table:   "articles",
+		Columns: ParseColumns("id", "title"),
+	}
+	i.ConflictKeys = Keys{"id"}
+	i.OnUpdateKeys = Keys{"title"}
+
+	assert.Equal(t, "INSERT INTO archive(id,title) SELECT id,title FROM articles WHERE true ON CONFLICT (id) DO UPDATE SET title = excluded.title", i.Render(SQLite))
+	assert.Equal(t, "INSERT INTO archive(id,title) SELECT id,title FROM articles ON DUPLICATE KEY UPDATE title = VALUES(title)", i.Render(MySQL))
+
+	i.Select.Where = ParseWhere("id > ?", 10)
+	assert.Equal(t, "INSERT INTO archive(id,title) SELECT id,title FROM articles WHERE id > ? ON CONFLICT (id) DO UPDATE SET title = excluded.title", i.Render(SQLite))
+	assert.Equal(t, []any{10}, i.Args())
+}
@@ -27,6 +27,23 @@ func ParseLeftJoin(t string, on On) Join {
 	}
 }
 
+// ParseJoinSource create a new Join on a source (table or derived table).
+func ParseJoinSource(src Source, on On) Join {
+	return Join{
+		From: src,
+		On:   on,
+	}
+}
+
+// ParseLeftJoinSource create a new left Join on a source (table or derived table).
+func ParseLeftJoinSource(src Source, on On) Join {
+	return Join{
+		From: src,
+		Type: "LEFT",
+		On:   on,
+	}
+}
+
 // NewJoin create a new Join.
 func NewJoin() Join {
 	return Join{
@@ -58,9 +75,14 @@ func (j Joins) Len() int {
 
 // String convert Joins to string.
 func (j Joins) String() string {
+	return j.render(DefaultDialect)
+}
+
+// render convert Joins to string with "?" placeholders.
+func (j Joins) render(d Dialect) string {
 	str := strings.Builder{}
 	for i := range j {
-		str.WriteString(j[i].String())
+		str.WriteString(j[i].render(d))
 	}
 	return str.String()
 }
@@ -77,27 +99,36 @@ func (j Joins) Args() []any {
 // Join is the representation of the Join.
 type Join struct {
 	Table string
-	Type  string
-	On    On
+
+	// From is the source of the join, it overrides Table when set.
+	From Source
+	Type string
+	On   On
 }
 
 // String convert Join to string.
 func (j *Join) String() string {
+	return j.render(DefaultDialect)
+}
+
+// render convert Join to string with "?" placeholders.
+func (j *Join) render(d Dialect) string {
 	str := strings.Builder{}
 	if j.Type != "" {
-		fmt.Fprintf(&str, " %s JOIN %s", j.Type, j.Table)
+		fmt.Fprintf(&str, " %s JOIN %s", j.Type, renderSource(d, j.From, j.Table))
 	} else {
-		fmt.Fprintf(&str, " JOIN %s", j.Table)
+		fmt.Fprintf(&str, " JOIN %s", renderSource(d, j.From, j.Table))
 	}
 
 	if j.On.Len() > 0 {
 		str.WriteString(onKeyword)
-		str.WriteString(j.On.String())
+		str.WriteString(renderNested(d, j.On))
 	}
 	return str.String()
 }
 
 // Args return the arguments for the join.
 func (j *Join) Args() (args []any) {
-	return j.On.Args()
+	args = append(args, j.From.Args()...)
+	return append(args, j.On.Args()...)
 }
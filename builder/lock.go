@@ -0,0 +1,85 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"strings"
+)
+
+// Locking modes of the Lock clause.
+const (
+	LockForUpdate   = "FOR UPDATE"
+	LockForShare    = "FOR SHARE"
+	LockInShareMode = "LOCK IN SHARE MODE"
+)
+
+const (
+	ofKeyword         = " OF "
+	noWaitKeyword     = " NOWAIT"
+	skipLockedKeyword = " SKIP LOCKED"
+)
+
+// Locker is implemented by statements that can lock rows.
+type Locker interface {
+	IsLocked() bool
+}
+
+// ForUpdate create a new Lock "FOR UPDATE".
+func ForUpdate(of ...string) Lock {
+	return Lock{
+		Mode: LockForUpdate,
+		Of:   of,
+	}
+}
+
+// ForShare create a new Lock "FOR SHARE".
+func ForShare(of ...string) Lock {
+	return Lock{
+		Mode: LockForShare,
+		Of:   of,
+	}
+}
+
+// Lock is the representation of the locking clause of a select.
+type Lock struct {
+	Mode       string
+	Of         Keys
+	NoWait     bool
+	SkipLocked bool
+}
+
+// IsZero says if there is no lock.
+func (l Lock) IsZero() bool {
+	return l.Mode == ""
+}
+
+// lock write the locking clause shared by MySQL & PostgreSQL.
+func lock(l Lock) string {
+	if l.IsZero() {
+		return ""
+	}
+
+	q := strings.Builder{}
+	q.WriteRune(' ')
+	q.WriteString(l.Mode)
+
+	// LOCK IN SHARE MODE does not support any option.
+	if l.Mode == LockInShareMode {
+		return q.String()
+	}
+
+	if len(l.Of) > 0 {
+		q.WriteString(ofKeyword)
+		q.WriteString(strings.Join(l.Of, ","))
+	}
+
+	if l.NoWait {
+		q.WriteString(noWaitKeyword)
+	} else if l.SkipLocked {
+		q.WriteString(skipLockedKeyword)
+	}
+	return q.String()
+}
@@ -0,0 +1,37 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectLock(t *testing.T) {
+	s := Select{
+		Table: "orders",
+		Where: ParseWhere("id = ?", 1),
+	}
+	assert.False(t, s.IsLocked())
+
+	s.Lock = ForUpdate()
+	assert.True(t, s.IsLocked())
+	assert.Equal(t, " SELECT * FROM orders WHERE id = ? FOR UPDATE", s.String())
+
+	s.Lock.SkipLocked = true
+	s.Limit = 1
+	assert.Equal(t, " SELECT * FROM orders WHERE id = ? LIMIT 1 OFFSET 0  FOR UPDATE SKIP LOCKED", s.String())
+
+	s.Lock = ForShare("orders")
+	s.Lock.NoWait = true
+	assert.Equal(t, " SELECT * FROM \"orders\" WHERE id = $1 LIMIT 1 OFFSET 0  FOR SHARE OF \"orders\" NOWAIT", s.Render(Quoted(PostgreSQL)))
+	assert.Equal(t, " SELECT * FROM orders WHERE id = ? LIMIT 1 OFFSET 0 ", s.Render(SQLite))
+
+	s.Lock = Lock{Mode: LockInShareMode, NoWait: true}
+	assert.Equal(t, " SELECT * FROM orders WHERE id = ? LIMIT 1 OFFSET 0  LOCK IN SHARE MODE", s.Render(MySQL))
+	assert.Equal(t, " SELECT * FROM orders WHERE id = $1 LIMIT 1 OFFSET 0  FOR SHARE NOWAIT", s.Render(PostgreSQL))
+}
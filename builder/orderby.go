@@ -5,6 +5,10 @@
 
 package builder
 
+import (
+	"strings"
+)
+
 const (
 	orderByKeyword = " ORDER BY "
 )
@@ -18,7 +22,8 @@ func NewOrderBy() OrderBy {
 // This function should be called to initiate the OrderBy field.
 func ParseOrderBy(cols ...string) OrderBy {
 	out := &orderBy{}
-	return out.Add(cols...)
+	out.Add(cols...)
+	return out
 }
 
 // ASC returns ASC if v is true else DESC.
@@ -47,5 +52,31 @@ type orderBy struct {
 }
 
 func (ob *orderBy) String() string {
-	return ob.str.String()
+	return ob.render(DefaultDialect)
+}
+
+func (ob *orderBy) render(d Dialect) string {
+	out := make([]string, 0, len(ob.list))
+	for _, col := range ob.list {
+		if _, ok := d.(*quotedDialect); !ok || strings.Contains(col, "(") {
+			out = append(out, ident(d, col))
+			continue
+		}
+
+		for _, entry := range strings.Split(col, ",") {
+			out = append(out, orderByEntry(d, strings.TrimSpace(entry)))
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// orderByEntry renders a column followed by an optional direction.
+func orderByEntry(d Dialect, entry string) string {
+	fields := strings.Fields(entry)
+	if n := len(fields); n == 2 {
+		if dir := strings.ToUpper(fields[1]); dir == "ASC" || dir == "DESC" {
+			return ident(d, fields[0]) + " " + dir
+		}
+	}
+	return ident(d, entry)
 }
@@ -6,7 +6,6 @@
 package builder
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -18,6 +17,7 @@ const (
 // NewSelect create a new select.
 func NewSelect(t string) *Select {
 	return &Select{
+		With:    CTEs{},
 		Table:   t,
 		Columns: NewColumns(),
 		Joins:   Joins{},
@@ -30,7 +30,11 @@ func NewSelect(t string) *Select {
 
 // Select is the representation of the Select statement.
 type Select struct {
-	Table   string
+	With  CTEs
+	Table string
+
+	// From is the source of the select, it overrides Table when set.
+	From    Source
 	Columns Columns
 	Joins   Joins
 	Where   Where
@@ -39,59 +43,88 @@ type Select struct {
 	OrderBy OrderBy
 	Limit   uint64
 	Offset  uint64
+
+	// Lock is the locking clause, it requires a transaction.
+	Lock Lock
 }
 
 // String convert the select to string.
 func (s *Select) String() string {
+	return s.Render(DefaultDialect)
+}
+
+// Render convert the select to string for the dialect d.
+func (s *Select) Render(d Dialect) string {
+	return Rebind(d, s.render(d))
+}
+
+// render convert the select to string with "?" placeholders.
+func (s *Select) render(d Dialect) string {
 	q := strings.Builder{}
+	if s.With.Len() > 0 {
+		q.WriteString(s.With.render(d))
+	}
+
 	q.WriteString(selectKeyword)
 	if s.Columns != nil {
-		q.WriteString(s.Columns.String())
+		q.WriteString(renderNested(d, s.Columns))
 	} else {
 		q.WriteString("*")
 	}
 	q.WriteString(fromKeyword)
-	q.WriteString(s.Table)
+	q.WriteString(renderSource(d, s.From, s.Table))
 
 	// Joins section
 	if s.Joins.Len() > 0 {
-		q.WriteString(s.Joins.String())
+		q.WriteString(s.Joins.render(d))
 	}
 
 	// Where clause
 	if s.Where != nil && s.Where.Len() > 0 {
 		q.WriteString(whereKeyword)
-		q.WriteString(s.Where.String())
+		q.WriteString(renderNested(d, s.Where))
 	}
 
 	// Group By clause
 	if s.GroupBy != nil && s.GroupBy.Len() > 0 {
 		q.WriteString(groupByKeyword)
-		q.WriteString(s.GroupBy.String())
+		q.WriteString(renderNested(d, s.GroupBy))
 	}
 
 	// Having clause
 	if s.Having != nil && s.Having.Len() > 0 {
 		q.WriteString(havingKeyword)
-		q.WriteString(s.Having.String())
+		q.WriteString(renderNested(d, s.Having))
 	}
 
 	// OrderBy clause
 	if s.OrderBy != nil && s.OrderBy.Len() > 0 {
 		q.WriteString(orderByKeyword)
-		q.WriteString(s.OrderBy.String())
+		q.WriteString(renderNested(d, s.OrderBy))
 	}
 
 	// Pagination
-	if s.Limit > 0 {
-		fmt.Fprintf(&q, " LIMIT %d OFFSET %d ", s.Limit, s.Offset)
+	q.WriteString(d.Limit(s.Limit, s.Offset))
+
+	// Locking clause
+	if !s.Lock.IsZero() {
+		l := s.Lock
+		l.Of = identKeys(d, l.Of)
+		q.WriteString(d.Lock(l))
 	}
 
 	return q.String()
 }
 
+// IsLocked says if the select lock the rows.
+func (s *Select) IsLocked() bool {
+	return !s.Lock.IsZero()
+}
+
 // Args compute the arguments of the select query.
 func (s *Select) Args() (out []any) {
+	out = append(out, s.With.Args()...)
+	out = append(out, s.From.Args()...)
 	out = append(out, s.Joins.Args()...)
 	if s.Where != nil {
 		out = append(out, s.Where.Args()...)
@@ -0,0 +1,76 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"strings"
+)
+
+// NewSource create a new Source from a table.
+func NewSource(t string) Source {
+	return Source{
+		Table: t,
+	}
+}
+
+// NewDerived create a new Source from a subquery (derived table).
+func NewDerived(s Stmt, alias string) Source {
+	return Source{
+		Stmt:  s,
+		Alias: alias,
+	}
+}
+
+// Source is the representation of a table or a derived table.
+type Source struct {
+	Table string
+	Stmt  Stmt
+	Alias string
+}
+
+// IsZero says if the source is empty.
+func (s Source) IsZero() bool {
+	return s.Table == "" && s.Stmt == nil
+}
+
+// String convert Source to string.
+func (s Source) String() string {
+	return s.render(DefaultDialect)
+}
+
+// render convert Source to string with "?" placeholders.
+func (s Source) render(d Dialect) string {
+	str := strings.Builder{}
+	if s.Stmt != nil {
+		str.WriteRune('(')
+		str.WriteString(renderNested(d, s.Stmt))
+		str.WriteRune(')')
+	} else {
+		str.WriteString(ident(d, s.Table))
+	}
+
+	if s.Alias != "" {
+		str.WriteRune(' ')
+		str.WriteString(ident(d, s.Alias))
+	}
+	return str.String()
+}
+
+// Args return the arguments of the source.
+func (s Source) Args() []any {
+	if s.Stmt != nil {
+		return s.Stmt.Args()
+	}
+	return nil
+}
+
+// renderSource render the source if set, or the table.
+func renderSource(d Dialect, s Source, table string) string {
+	if !s.IsZero() {
+		return s.render(d)
+	}
+	return ident(d, table)
+}
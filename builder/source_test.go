@@ -0,0 +1,81 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectFromDerived(t *testing.T) {
+	s := Select{
+		From: NewDerived(&Select{
+			Table:   "articles",
+			Columns: ParseColumns("author_id", "COUNT(*) AS total"),
+			Where:   ParseWhere("created_at > ?", "2019-01-01"),
+			GroupBy: ParseGroupBy("author_id"),
+		}, "x"),
+		Joins: Joins{ParseLeftJoinSource(NewSource("authors a"), ParseOn("a.id = x.author_id AND a.active = ?", true))},
+		Where: ParseWhere("x.total > ?", 10),
+	}
+
+	assert.Equal(t, " SELECT * FROM (SELECT author_id,COUNT(*) AS total FROM articles WHERE created_at > ? GROUP BY author_id) x LEFT JOIN authors a ON a.id = x.author_id AND a.active = ? WHERE x.total > ?", s.String())
+	assert.Equal(t, []any{"2019-01-01", true, 10}, s.Args())
+}
+
+func TestJoinDerived(t *testing.T) {
+	last := &Select{
+		Table:   "articles",
+		Columns: ParseColumns("author_id", "MAX(created_at) AS last_at"),
+		Where:   ParseWhere("status = ?", 1),
+		GroupBy: ParseGroupBy("author_id"),
+	}
+
+	u := NewUpdate("authors a")
+	u.Joins.Add(ParseJoinSource(NewDerived(last, "l"), ParseOn("l.author_id = a.id")))
+	u.Values["a.active"] = false
+	u.Where.And("l.last_at < ?", "2019-01-01")
+
+	assert.Equal(t, "UPDATE authors a JOIN (SELECT author_id,MAX(created_at) AS last_at FROM articles WHERE status = ? GROUP BY author_id) l ON l.author_id = a.id SET a.active = ? WHERE l.last_at < ?", u.String())
+	assert.Equal(t, []any{1, false, "2019-01-01"}, u.Args())
+
+	d := NewDelete("authors a")
+	d.Joins.Add(ParseJoinSource(NewDerived(last, "l"), ParseOn("l.author_id = a.id")))
+	d.Where.And("l.last_at < ?", "2019-01-01")
+
+	assert.Equal(t, "DELETE a FROM authors a JOIN (SELECT author_id,MAX(created_at) AS last_at FROM articles WHERE status = ? GROUP BY author_id) l ON l.author_id = a.id WHERE l.last_at < ?", d.String())
+	assert.Equal(t, []any{1, "2019-01-01"}, d.Args())
+}
+
+func TestUpdateDeleteFrom(t *testing.T) {
+	last := &Select{
+		Table:   "articles",
+		Columns: ParseColumns("author_id", "MAX(created_at) AS last_at"),
+		Where:   ParseWhere("status = ?", 1),
+		GroupBy: ParseGroupBy("author_id"),
+	}
+
+	u := NewUpdate("authors a")
+	u.From = NewDerived(last, "l")
+	u.Values["active"] = false
+	u.Where.And("l.author_id = a.id AND l.last_at < ?", "2019-01-01")
+
+	assert.Equal(t, "UPDATE authors a SET active = $1 FROM (SELECT author_id,MAX(created_at) AS last_at FROM articles WHERE status = $2 GROUP BY author_id) l WHERE l.author_id = a.id AND l.last_at < $3", u.Render(PostgreSQL))
+	assert.Equal(t, []any{false, 1, "2019-01-01"}, u.Args())
+	assert.NoError(t, Check(SQLite, u))
+	assert.ErrorIs(t, Check(MySQL, u), ErrUnsupported)
+
+	d := NewDelete("authors a")
+	d.From = NewDerived(last, "l")
+	d.Where.And("l.author_id = a.id AND l.last_at < ?", "2019-01-01")
+
+	assert.Equal(t, "DELETE  FROM authors a USING (SELECT author_id,MAX(created_at) AS last_at FROM articles WHERE status = $1 GROUP BY author_id) l WHERE l.author_id = a.id AND l.last_at < $2", d.Render(PostgreSQL))
+	assert.Equal(t, "DELETE a FROM authors a, (SELECT author_id,MAX(created_at) AS last_at FROM articles WHERE status = ? GROUP BY author_id) l WHERE l.author_id = a.id AND l.last_at < ?", d.String())
+	assert.Equal(t, []any{1, "2019-01-01"}, d.Args())
+	assert.NoError(t, Check(MySQL, d))
+	assert.ErrorIs(t, Check(SQLite, d), ErrUnsupported)
+}
@@ -11,6 +11,12 @@ import (
 	"strings"
 )
 
+// Stmt is the representation of a statement that can be nested into another one.
+type Stmt interface {
+	String() string
+	Args() []any
+}
+
 // Slicer abstract a slice (until generics are supported)
 type Slicer interface {
 	Len() int
@@ -39,11 +45,25 @@ func (v H) Keys() Keys {
 // Binds is used to bind column to another one. (Raw version, no secure)
 type Binds map[string]string
 
-// String
+// String convert Binds to string.
 func (b Binds) String() string {
+	return b.render(DefaultDialect)
+}
+
+// render convert Binds to string, sorted by key.
+func (b Binds) render(d Dialect) string {
+	keys := make(Keys, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	str := strings.Builder{}
-	for k, v := range b {
-		fmt.Fprintf(&str, "%s = %s", k, v)
+	for i, k := range keys {
+		if i > 0 {
+			str.WriteRune(',')
+		}
+		fmt.Fprintf(&str, "%s = %s", ident(d, k), b[k])
 	}
 	return str.String()
 }
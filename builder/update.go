@@ -18,6 +18,7 @@ const (
 // NewUpdate create a new update.
 func NewUpdate(t string) *Update {
 	return &Update{
+		With:   CTEs{},
 		Table:  t,
 		Values: H{},
 		Binds:  Binds{},
@@ -28,18 +29,44 @@ func NewUpdate(t string) *Update {
 
 // Update is the representation of an Update statement.
 type Update struct {
+	With   CTEs
 	Table  string
 	Values H
 	Binds  Binds
-	Joins  Joins
-	Where  Where
+
+	// From is a source used by the values & the conditions (UPDATE ... FROM
+	// on PostgreSQL & SQLite), it is not supported by MySQL which uses Joins.
+	From  Source
+	Joins Joins
+	Where Where
 }
 
 // String convert the update to string.
 func (u *Update) String() string {
+	return u.Render(DefaultDialect)
+}
+
+// Render convert the update to string for the dialect d.
+func (u *Update) Render(d Dialect) string {
+	return Rebind(d, u.render(d))
+}
+
+// render convert the update to string with "?" placeholders.
+func (u *Update) render(d Dialect) string {
 	q := strings.Builder{}
+	if u.With.Len() > 0 {
+		q.WriteString(u.With.render(d))
+		q.WriteRune(' ')
+	}
+
 	q.WriteString(updateKeyword)
-	q.WriteString(u.Table)
+	q.WriteString(ident(d, u.Table))
+
+	// Joins section
+	if u.Joins.Len() > 0 {
+		q.WriteString(u.Joins.render(d))
+	}
+
 	q.WriteString(setKeyword)
 
 	// Values
@@ -49,31 +76,55 @@ func (u *Update) String() string {
 
 		for i, k := range keys {
 			if i < n {
-				fmt.Fprintf(&q, "%s = ?,", k)
+				fmt.Fprintf(&q, "%s = ?,", ident(d, k))
 			} else {
-				fmt.Fprintf(&q, "%s = ?", k)
+				fmt.Fprintf(&q, "%s = ?", ident(d, k))
 			}
 		}
 	}
 
-	// Joins section
-	if u.Joins.Len() > 0 {
-		q.WriteString(u.Joins.String())
+	// Binds
+	if len(u.Binds) > 0 {
+		if len(u.Values) > 0 {
+			q.WriteRune(',')
+		}
+		q.WriteString(u.Binds.render(d))
+	}
+
+	if !u.From.IsZero() {
+		q.WriteString(fromKeyword)
+		q.WriteString(u.From.render(d))
 	}
 
 	// WHERE clause
 	if u.Where != nil && u.Where.Len() > 0 {
 		q.WriteString(whereKeyword)
-		q.WriteString(u.Where.String())
+		q.WriteString(renderNested(d, u.Where))
 	}
 	return q.String()
 }
 
+// check returns an error if the update can't be rendered for the dialect d.
+func (u *Update) check(d Dialect) error {
+	switch {
+	case strings.TrimSpace(u.Table) == "":
+		return ErrMissingTable
+	case u.Joins.Len() > 0 && !isDialect(d, MySQL):
+		return fmt.Errorf("%w: UPDATE with joins on %s", ErrUnsupported, d.Name())
+	case !u.From.IsZero() && isDialect(d, MySQL):
+		return fmt.Errorf("%w: UPDATE with a source on %s", ErrUnsupported, d.Name())
+	}
+	return nil
+}
+
 // Args compute the arguments of the update statement.
 func (u *Update) Args() (out []any) {
+	out = append(out, u.With.Args()...)
+	out = append(out, u.Joins.Args()...)
 	for _, k := range u.Values.Keys() {
 		out = append(out, u.Values[k])
 	}
+	out = append(out, u.From.Args()...)
 
 	if u.Where != nil {
 		out = append(out, u.Where.Args()...)
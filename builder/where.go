@@ -17,6 +17,9 @@ const (
 	orKeyword    = " OR "
 	inKeyword    = " IN "
 	notInKeyword = " NOT IN "
+
+	existsKeyword    = "EXISTS "
+	notExistsKeyword = "NOT EXISTS "
 )
 
 // NewWhere create a new Where.
@@ -59,6 +62,25 @@ type Where interface {
 	// AndNotIn add a new condition "AND" with the operator NOT IN.
 	AndNotIn(col string, s Slicer) Where
 
+	// AndInSelect add a new condition "AND" with the operator IN and a subquery.
+	AndInSelect(col string, s Stmt) Where
+
+	// AndNotInSelect add a new condition "AND" with the operator NOT IN and a subquery.
+	AndNotInSelect(col string, s Stmt) Where
+
+	// AndExists add a new condition "AND" with the operator EXISTS.
+	AndExists(s Stmt) Where
+
+	// AndNotExists add a new condition "AND" with the operator NOT EXISTS.
+	AndNotExists(s Stmt) Where
+
+	// AndScalar add a new condition "AND" comparing col to a scalar subquery.
+	// AND col op ( subquery )
+	AndScalar(col, op string, s Stmt) Where
+
+	// AndCond add new typed conditions "AND".
+	AndCond(c ...Cond) Where
+
 	// AndWhere merge Where's inside parenthesis with AND condition.
 	// AND ( where )
 	AndWhere(in ...Where) Where
@@ -75,37 +97,78 @@ type Where interface {
 	// OrNotIn add a new condition "OR" with the operator NOT IN.
 	OrNotIn(col string, s Slicer) Where
 
+	// OrInSelect add a new condition "OR" with the operator IN and a subquery.
+	OrInSelect(col string, s Stmt) Where
+
+	// OrNotInSelect add a new condition "OR" with the operator NOT IN and a subquery.
+	OrNotInSelect(col string, s Stmt) Where
+
+	// OrExists add a new condition "OR" with the operator EXISTS.
+	OrExists(s Stmt) Where
+
+	// OrNotExists add a new condition "OR" with the operator NOT EXISTS.
+	OrNotExists(s Stmt) Where
+
+	// OrScalar add a new condition "OR" comparing col to a scalar subquery.
+	// OR col op ( subquery )
+	OrScalar(col, op string, s Stmt) Where
+
+	// OrCond add new typed conditions "OR".
+	OrCond(c ...Cond) Where
+
 	// OrWhere merge Where's inside parenthesis with OR condition.
 	// OR ( where )
 	OrWhere(in ...Where) Where
 
-	// Len says the length of the string.
+	// Len returns the number of conditions.
 	Len() int
 }
 
+// condition is a Cond of the where combined with the previous ones.
+type condition struct {
+	keyword string
+	expr    Cond
+}
+
 type where struct {
-	str  strings.Builder
-	args []any
+	conds []condition
 }
 
 func (w *where) Args() []any {
-	return w.args
+	var out []any
+	for _, c := range w.conds {
+		out = append(out, c.expr.Args()...)
+	}
+	return out
 }
 
 func (w *where) String() string {
-	return w.str.String()
+	return w.render(DefaultDialect)
 }
 
-func (w *where) And(str string, args ...any) Where {
-	if w.str.Len() > 0 {
-		w.str.WriteString(andKeyword)
+func (w *where) render(d Dialect) string {
+	str := strings.Builder{}
+	for i, c := range w.conds {
+		if i > 0 {
+			str.WriteString(c.keyword)
+		}
+		str.WriteString(c.expr.render(d))
 	}
+	return str.String()
+}
 
-	w.str.WriteString(str)
-	w.args = append(w.args, args...)
+// add a new Cond with the given keyword, empty conditions are ignored.
+func (w *where) add(keyword string, expr Cond) Where {
+	if !isEmpty(expr) {
+		w.conds = append(w.conds, condition{keyword: keyword, expr: expr})
+	}
 	return w
 }
 
+func (w *where) And(str string, args ...any) Where {
+	return w.add(andKeyword, &rawExpr{str: str, args: args})
+}
+
 func (w *where) AndIf(str string, arg any) Where {
 	if !reflect.ValueOf(arg).IsZero() {
 		if strings.Contains(str, "?") {
@@ -119,14 +182,41 @@ func (w *where) AndIf(str string, arg any) Where {
 
 func (w *where) AndIn(col string, s Slicer) Where {
 	if n := s.Len(); n > 0 {
-		w.And(whereIn(col, inKeyword, s), s.S()...)
+		w.add(andKeyword, In(col, s))
 	}
 	return w
 }
 
 func (w *where) AndNotIn(col string, s Slicer) Where {
 	if n := s.Len(); n > 0 {
-		w.And(whereIn(col, notInKeyword, s), s.S()...)
+		w.add(andKeyword, NotIn(col, s))
+	}
+	return w
+}
+
+func (w *where) AndInSelect(col string, s Stmt) Where {
+	return w.add(andKeyword, In(col, s))
+}
+
+func (w *where) AndNotInSelect(col string, s Stmt) Where {
+	return w.add(andKeyword, NotIn(col, s))
+}
+
+func (w *where) AndExists(s Stmt) Where {
+	return w.add(andKeyword, &subqueryExpr{prefix: existsKeyword, s: s})
+}
+
+func (w *where) AndNotExists(s Stmt) Where {
+	return w.add(andKeyword, &subqueryExpr{prefix: notExistsKeyword, s: s})
+}
+
+func (w *where) AndScalar(col, op string, s Stmt) Where {
+	return w.add(andKeyword, &compareCond{col: col, op: op, v: s})
+}
+
+func (w *where) AndCond(c ...Cond) Where {
+	for _, v := range c {
+		w.add(andKeyword, v)
 	}
 	return w
 }
@@ -134,20 +224,14 @@ func (w *where) AndNotIn(col string, s Slicer) Where {
 func (w *where) AndWhere(in ...Where) Where {
 	for _, v := range in {
 		if v.Len() > 0 {
-			w.And(fmt.Sprintf("(%s)", v.String()), v.Args()...)
+			w.add(andKeyword, &groupExpr{w: v})
 		}
 	}
 	return w
 }
 
 func (w *where) Or(str string, args ...any) Where {
-	if w.str.Len() > 0 {
-		w.str.WriteString(orKeyword)
-	}
-
-	w.str.WriteString(str)
-	w.args = append(w.args, args...)
-	return w
+	return w.add(orKeyword, &rawExpr{str: str, args: args})
 }
 
 func (w *where) OrIf(str string, arg any) Where {
@@ -163,14 +247,41 @@ func (w *where) OrIf(str string, arg any) Where {
 
 func (w *where) OrIn(col string, s Slicer) Where {
 	if n := s.Len(); n > 0 {
-		w.Or(whereIn(col, inKeyword, s), s.S()...)
+		w.add(orKeyword, In(col, s))
 	}
 	return w
 }
 
 func (w *where) OrNotIn(col string, s Slicer) Where {
 	if n := s.Len(); n > 0 {
-		w.Or(whereIn(col, notInKeyword, s), s.S()...)
+		w.add(orKeyword, NotIn(col, s))
+	}
+	return w
+}
+
+func (w *where) OrInSelect(col string, s Stmt) Where {
+	return w.add(orKeyword, In(col, s))
+}
+
+func (w *where) OrNotInSelect(col string, s Stmt) Where {
+	return w.add(orKeyword, NotIn(col, s))
+}
+
+func (w *where) OrExists(s Stmt) Where {
+	return w.add(orKeyword, &subqueryExpr{prefix: existsKeyword, s: s})
+}
+
+func (w *where) OrNotExists(s Stmt) Where {
+	return w.add(orKeyword, &subqueryExpr{prefix: notExistsKeyword, s: s})
+}
+
+func (w *where) OrScalar(col, op string, s Stmt) Where {
+	return w.add(orKeyword, &compareCond{col: col, op: op, v: s})
+}
+
+func (w *where) OrCond(c ...Cond) Where {
+	for _, v := range c {
+		w.add(orKeyword, v)
 	}
 	return w
 }
@@ -178,17 +289,68 @@ func (w *where) OrNotIn(col string, s Slicer) Where {
 func (w *where) OrWhere(in ...Where) Where {
 	for _, v := range in {
 		if v.Len() > 0 {
-			w.Or(fmt.Sprintf("(%s)", v.String()), v.Args()...)
+			w.add(orKeyword, &groupExpr{w: v})
 		}
 	}
 	return w
 }
 
 func (w *where) Len() int {
-	return w.str.Len()
+	return len(w.conds)
+}
+
+// rawExpr is a raw condition with "?" placeholders.
+type rawExpr struct {
+	str  string
+	args []any
+}
+
+func (e *rawExpr) String() string {
+	return e.str
+}
+
+func (e *rawExpr) render(Dialect) string {
+	return e.str
+}
+
+func (e *rawExpr) Args() []any {
+	return e.args
+}
+
+// subqueryExpr is a condition on a subquery: prefix ( subquery ).
+type subqueryExpr struct {
+	prefix string
+	s      Stmt
+}
+
+func (e *subqueryExpr) String() string {
+	return e.render(DefaultDialect)
+}
+
+func (e *subqueryExpr) render(d Dialect) string {
+	return fmt.Sprintf("%s(%s)", e.prefix, renderNested(d, e.s))
+}
+
+func (e *subqueryExpr) Args() []any {
+	return e.s.Args()
+}
+
+// groupExpr is a where merged inside parenthesis.
+type groupExpr struct {
+	w Where
+}
+
+func (e *groupExpr) String() string {
+	return e.render(DefaultDialect)
+}
+
+func (e *groupExpr) render(d Dialect) string {
+	if str := renderNested(d, e.w); str != "" {
+		return fmt.Sprintf("(%s)", str)
+	}
+	return ""
 }
 
-// whereIn
-func whereIn(col, keyword string, s Slicer) string {
-	return fmt.Sprintf("%s%s(%s)", col, keyword, strings.TrimRight(strings.Repeat("?,", s.Len()), ","))
+func (e *groupExpr) Args() []any {
+	return e.w.Args()
 }
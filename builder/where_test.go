@@ -86,3 +86,45 @@ func TestWhereAndOr(t *testing.T) {
 	w.Or("col2")
 	assert.Equal(t, "col1 = ? OR col2", w.String())
 }
+
+func TestWhereSubquery(t *testing.T) {
+	sub := &Select{
+		Table:   "authors",
+		Columns: ParseColumns("id"),
+		Where:   ParseWhere("nickname = ?", "kovacou"),
+	}
+
+	w := where{}
+	w.And("status = ?", 1)
+	w.AndInSelect("author_id", sub)
+	w.OrNotExists(&Select{Table: "comments c", Where: ParseWhere("c.article_id = a.id AND c.spam = ?", true)})
+	w.AndScalar("views", ">", &Select{Table: "articles", Columns: ParseColumns("AVG(views)"), Where: ParseWhere("author_id = ?", 2)})
+
+	assert.Equal(t, "status = ? AND author_id IN (SELECT id FROM authors WHERE nickname = ?) OR NOT EXISTS (SELECT * FROM comments c WHERE c.article_id = a.id AND c.spam = ?) AND views > (SELECT AVG(views) FROM articles WHERE author_id = ?)", w.String())
+	assert.Equal(t, []any{1, "kovacou", true, 2}, w.Args())
+
+	s := Select{Table: "articles a", Where: &w}
+	assert.Equal(t, " SELECT * FROM articles a WHERE status = $1 AND author_id IN (SELECT id FROM authors WHERE nickname = $2) OR NOT EXISTS (SELECT * FROM comments c WHERE c.article_id = a.id AND c.spam = $3) AND views > (SELECT AVG(views) FROM articles WHERE author_id = $4)", s.Render(PostgreSQL))
+}
+
+func TestWhereAndWhere(t *testing.T) {
+	w := where{}
+	w.And("col1 = ?", 1)
+	w.AndWhere(ParseWhere("col2 = ?", 2).Or("col3 = ?", 3), NewWhere())
+	w.OrWhere(MakeWhere(func(w Where) {
+		w.AndExists(NewQuery("SELECT 1 FROM test WHERE col4 = ?", 4))
+	}))
+
+	assert.Equal(t, "col1 = ? AND (col2 = ? OR col3 = ?) OR (EXISTS (SELECT 1 FROM test WHERE col4 = ?))", w.String())
+	assert.Equal(t, []any{1, 2, 3, 4}, w.Args())
+}
+
+func TestWhereLen(t *testing.T) {
+	w := NewWhere()
+	assert.Equal(t, 0, w.Len())
+
+	w.And("col1 = ?", 1).AndCond(AnyOf(), Not(AllOf())).AndWhere(NewWhere())
+	w.OrExists(&Select{Table: "test", Where: ParseWhere("col2 = ?", 2)})
+	assert.Equal(t, 2, w.Len())
+	assert.Equal(t, "col1 = ? OR EXISTS (SELECT * FROM test WHERE col2 = ?)", w.String())
+}
@@ -0,0 +1,114 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"strings"
+)
+
+const (
+	withKeyword      = "WITH "
+	recursiveKeyword = "RECURSIVE "
+	asKeyword        = " AS "
+)
+
+// NewCTE create a new common table expression.
+func NewCTE(name string, s Stmt, cols ...string) CTE {
+	return CTE{
+		Name:    name,
+		Columns: cols,
+		Stmt:    s,
+	}
+}
+
+// NewRecursiveCTE create a new recursive common table expression.
+// The statement s is usually a compound of an anchor & a recursive member.
+func NewRecursiveCTE(name string, s Stmt, cols ...string) CTE {
+	return CTE{
+		Name:      name,
+		Columns:   cols,
+		Stmt:      s,
+		Recursive: true,
+	}
+}
+
+// CTEs is the representation of the WITH clause.
+type CTEs []CTE
+
+// Add a new CTE to the list.
+func (c *CTEs) Add(ctes ...CTE) *CTEs {
+	*c = append(*c, ctes...)
+	return c
+}
+
+// Len return the number of CTE.
+func (c CTEs) Len() int {
+	return len(c)
+}
+
+// String convert CTEs to string.
+func (c CTEs) String() string {
+	return c.render(DefaultDialect)
+}
+
+// render convert CTEs to string with "?" placeholders.
+func (c CTEs) render(d Dialect) string {
+	str := strings.Builder{}
+	str.WriteString(withKeyword)
+	for i := range c {
+		if c[i].Recursive {
+			str.WriteString(recursiveKeyword)
+			break
+		}
+	}
+
+	for i := range c {
+		if i > 0 {
+			str.WriteRune(',')
+		}
+		str.WriteString(c[i].render(d))
+	}
+	return str.String()
+}
+
+// Args return the args of the CTEs.
+func (c CTEs) Args() []any {
+	out := []any{}
+	for i := range c {
+		out = append(out, c[i].Args()...)
+	}
+	return out
+}
+
+// CTE is the representation of a common table expression.
+type CTE struct {
+	Name      string
+	Columns   Keys
+	Stmt      Stmt
+	Recursive bool
+}
+
+// render convert CTE to string with "?" placeholders.
+func (c *CTE) render(d Dialect) string {
+	str := strings.Builder{}
+	str.WriteString(ident(d, c.Name))
+	if len(c.Columns) > 0 {
+		str.WriteRune('(')
+		str.WriteString(strings.Join(identKeys(d, c.Columns), ","))
+		str.WriteRune(')')
+	}
+
+	str.WriteString(asKeyword)
+	str.WriteRune('(')
+	str.WriteString(renderNested(d, c.Stmt))
+	str.WriteRune(')')
+	return str.String()
+}
+
+// Args return the arguments of the CTE.
+func (c *CTE) Args() []any {
+	return c.Stmt.Args()
+}
@@ -0,0 +1,58 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectWith(t *testing.T) {
+	s := NewSelect("recent")
+	s.With.Add(NewCTE("recent", &Select{
+		Table: "articles",
+		Where: ParseWhere("created_at > ?", "2019-01-01"),
+	}))
+	s.Where.And("author_id = ?", 1)
+
+	assert.Equal(t, "WITH recent AS (SELECT * FROM articles WHERE created_at > ?) SELECT * FROM recent WHERE author_id = ?", s.String())
+	assert.Equal(t, "WITH recent AS (SELECT * FROM articles WHERE created_at > $1) SELECT * FROM recent WHERE author_id = $2", s.Render(PostgreSQL))
+	assert.Equal(t, []any{"2019-01-01", 1}, s.Args())
+}
+
+func TestSelectWithRecursive(t *testing.T) {
+	s := NewSelect("tree")
+	s.With.Add(NewRecursiveCTE("tree", NewQuery(
+		"SELECT id, parent_id FROM categories WHERE id = ? UNION ALL SELECT c.id, c.parent_id FROM categories c JOIN tree t ON c.parent_id = t.id", 1,
+	), "id", "parent_id"))
+
+	assert.Equal(t, "WITH RECURSIVE tree(id,parent_id) AS (SELECT id, parent_id FROM categories WHERE id = ? UNION ALL SELECT c.id, c.parent_id FROM categories c JOIN tree t ON c.parent_id = t.id) SELECT * FROM tree", s.String())
+	assert.Equal(t, []any{1}, s.Args())
+}
+
+func TestUpdateDeleteWith(t *testing.T) {
+	cte := NewCTE("old", &Select{
+		Table:   "articles",
+		Columns: ParseColumns("id"),
+		Where:   ParseWhere("created_at < ?", "2019-01-01"),
+	})
+
+	u := NewUpdate("articles")
+	u.With.Add(cte)
+	u.Values["archived"] = true
+	u.Where.And("id IN (SELECT id FROM old)")
+
+	assert.Equal(t, "WITH old AS (SELECT id FROM articles WHERE created_at < ?) UPDATE articles SET archived = ? WHERE id IN (SELECT id FROM old)", u.String())
+	assert.Equal(t, []any{"2019-01-01", true}, u.Args())
+
+	d := NewDelete("articles")
+	d.With.Add(cte)
+	d.Where.And("id IN (SELECT id FROM old)")
+
+	assert.Equal(t, "WITH old AS (SELECT id FROM articles WHERE created_at < ?) DELETE  FROM articles WHERE id IN (SELECT id FROM old)", d.String())
+	assert.Equal(t, []any{"2019-01-01"}, d.Args())
+}
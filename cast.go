@@ -7,6 +7,7 @@ package database
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,6 +26,16 @@ func toInt(v string) int {
 	return int(out)
 }
 
+// toList convert a comma separated string to a list.
+func toList(v string) (out []string) {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return
+}
+
 // toDuration convert string to duration.
 func toDuration(v string) time.Duration {
 	out, _ := time.ParseDuration(v)
@@ -5,10 +5,19 @@
 
 package database
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestUnexportedToBool(t *testing.T) {
 }
 
 func TestUnexportedToInt(t *testing.T) {
 }
+
+func TestUnexportedToList(t *testing.T) {
+	assert.Nil(t, toList(""))
+	assert.Equal(t, []string{"a", "b:3307"}, toList(" a, ,b:3307 "))
+}
@@ -6,6 +6,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -23,24 +24,28 @@ var (
 	// Debug is a global mode, when set at true, it will override the
 	// configuration of the connections.
 	Debug bool
-
-	// m is the mutex that manage the pool of sqlx wrapper.
-	m sync.Mutex
-
-	// pool of sqlx wrapper.
-	cp = make([]*sqlx.DB, 0, 5)
-
-	// map of ...
-	cm = make(map[string]uint, 5)
 )
 
 type (
 	// MapMapper is the prototype to map a map result.
+	// The map is reused between rows, it must be copied to be retained.
 	MapMapper func(map[string]any)
 
 	// SliceMapper is the prototype to map a slice result.
 	SliceMapper func([]any)
 
+	// MapMapperErr is the prototype to map a map result with an error.
+	// Returning ErrStop stops the iteration without error.
+	MapMapperErr func(map[string]any) error
+
+	// SliceMapperErr is the prototype to map a slice result with an error.
+	// Returning ErrStop stops the iteration without error.
+	SliceMapperErr func([]any) error
+
+	// RowMapper is the prototype to map a typed row result.
+	// The Row is owned by the mapper and can be retained.
+	RowMapper func(Row)
+
 	// Connection is a connection to an database.
 	Connection interface {
 		DB() *sqlx.DB
@@ -48,6 +53,7 @@ type (
 		Connect() error
 		LastError() error
 		Ping() error
+		PingContext(context.Context) error
 		MustPing()
 		Close() error
 		SetLogger(out *log.Logger, err *log.Logger)
@@ -55,6 +61,7 @@ type (
 
 		// Statements
 		Exec(Stmt) (sql.Result, error)
+		ExecContext(context.Context, Stmt) (sql.Result, error)
 
 		// Queries
 		SelectMap(Stmt, MapMapper) (int, error)
@@ -62,36 +69,85 @@ type (
 		SelectMapRow(Stmt, MapMapper) (int, error)
 		SelectSliceRow(Stmt, SliceMapper) (int, error)
 
+		SelectMapContext(context.Context, Stmt, MapMapper) (int, error)
+		SelectSliceContext(context.Context, Stmt, SliceMapper) (int, error)
+		SelectMapRowContext(context.Context, Stmt, MapMapper) (int, error)
+		SelectSliceRowContext(context.Context, Stmt, SliceMapper) (int, error)
+
+		SelectMapErr(Stmt, MapMapperErr) (int, error)
+		SelectSliceErr(Stmt, SliceMapperErr) (int, error)
+		SelectMapErrContext(context.Context, Stmt, MapMapperErr) (int, error)
+		SelectSliceErrContext(context.Context, Stmt, SliceMapperErr) (int, error)
+
+		SelectRows(Stmt, RowMapper) (int, error)
+		SelectRow(Stmt, RowMapper) (int, error)
+		SelectRowsContext(context.Context, Stmt, RowMapper) (int, error)
+		SelectRowContext(context.Context, Stmt, RowMapper) (int, error)
+
 		QueryMap(string, MapMapper, ...any) (int, error)
 		QuerySlice(string, SliceMapper, ...any) (int, error)
 		QueryMapRow(string, MapMapper, ...any) (int, error)
 		QuerySliceRow(string, SliceMapper, ...any) (int, error)
 
+		QueryMapContext(context.Context, string, MapMapper, ...any) (int, error)
+		QuerySliceContext(context.Context, string, SliceMapper, ...any) (int, error)
+		QueryMapRowContext(context.Context, string, MapMapper, ...any) (int, error)
+		QuerySliceRowContext(context.Context, string, SliceMapper, ...any) (int, error)
+
+		Iterate(Stmt) (Rows, error)
+		IterateContext(context.Context, Stmt) (Rows, error)
+		QueryIterate(string, ...any) (Rows, error)
+		QueryIterateContext(context.Context, string, ...any) (Rows, error)
+
+		QueryMapErr(string, MapMapperErr, ...any) (int, error)
+		QuerySliceErr(string, SliceMapperErr, ...any) (int, error)
+		QueryMapErrContext(context.Context, string, MapMapperErr, ...any) (int, error)
+		QuerySliceErrContext(context.Context, string, SliceMapperErr, ...any) (int, error)
+
+		QueryRows(string, RowMapper, ...any) (int, error)
+		QueryRow(string, RowMapper, ...any) (int, error)
+		QueryRowsContext(context.Context, string, RowMapper, ...any) (int, error)
+		QueryRowContext(context.Context, string, RowMapper, ...any) (int, error)
+
 		// Context
 		Context(...string) Connection
 		Done()
 		HasContext() bool
 		RunContext(...ContextFunc) error
 
+		// Replicas
+		Primary() Connection
+
+		// Prepared statements
+		StmtCacheStats() StmtCacheStats
+
 		// Tx
 		IsTx() bool
+		IsSavepoint() bool
+		AfterCommit(...TxHook) error
+		AfterRollback(...TxHook) error
+		SetTxLeakHook(TxLeakHook)
 		Tx(...sql.IsolationLevel) (Connection, error)
+		TxContext(context.Context, ...sql.IsolationLevel) (Connection, error)
+		TxWith(TxOptions) (Connection, error)
+		TxWithContext(context.Context, TxOptions) (Connection, error)
 		Commit() error
 		Rollback() error
 		RunTx(sql.IsolationLevel, ...TxFunc) error
+		RunTxContext(context.Context, sql.IsolationLevel, ...TxFunc) error
+		RunTxWith(TxOptions, ...TxFunc) error
+		RunTxWithContext(context.Context, TxOptions, ...TxFunc) error
+		RunTxRetry(RetryPolicy, sql.IsolationLevel, ...TxFunc) error
+		RunTxRetryContext(context.Context, RetryPolicy, sql.IsolationLevel, ...TxFunc) error
+		RunTxRetryWith(RetryPolicy, TxOptions, ...TxFunc) error
+		RunTxRetryWithContext(context.Context, RetryPolicy, TxOptions, ...TxFunc) error
 	}
 )
 
-// Close closes active connections in the pool.
+// Close closes active connections in the pool and releases them.
 // it closes any profiler running in background.
 func Close() {
-	m.Lock()
-	defer m.Unlock()
-	for _, dbx := range cp {
-		if dbx != nil {
-			_ = dbx.Close()
-		}
-	}
+	closeAll()
 }
 
 // Open opens a database from default environement.
@@ -168,13 +224,11 @@ func open(e *Environment, once bool, env string, dbx *sqlx.DB, logger []*log.Log
 		m:   &sync.Mutex{},
 	}
 
-	if once {
-		m.Lock()
-
-		m.Unlock()
+	if len(conn.env.Hosts) > 0 {
+		conn.failover = &failover{}
 	}
 
-	conn.id, conn.dbx = createNewConnection(once, cfg.Alias)
+	register(conn, once, dbx)
 
 	if conn.hasVerbose() {
 		if n := len(logger); n > 0 {
@@ -203,33 +257,13 @@ func open(e *Environment, once bool, env string, dbx *sqlx.DB, logger []*log.Log
 		}
 	}
 
+	if len(conn.env.Replicas) > 0 {
+		conn.replicas = replicaSetOf(conn, once)
+	}
+
 	if conn.env.Autoconnect {
 		conn.Connect()
 	}
 
 	return conn, nil
 }
-
-// createNewConnection in the pool "cp".
-func createNewConnection(once bool, alias string, dbx ...*sqlx.DB) (uint, **sqlx.DB) {
-	m.Lock()
-	defer m.Unlock()
-
-	if once {
-		if i, ok := cm[alias]; ok && cp[i] != nil {
-			return i, &cp[i]
-		}
-	}
-
-	cid := uint(len(cp))
-	if len(dbx) > 0 {
-		cp = append(cp, dbx[0])
-	} else {
-		cp = append(cp, nil)
-	}
-	if once {
-		cm[alias] = cid
-	}
-
-	return cid, &cp[cid]
-}
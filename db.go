@@ -6,9 +6,12 @@
 package database
 
 import (
+	"context"
 	"database/sql/driver"
+	"errors"
 	"log"
 	"sync"
+	"time"
 
 	// Loading mysql driver by default.
 	_ "github.com/go-sql-driver/mysql"
@@ -18,13 +21,27 @@ import (
 
 // db is a wrapper around sqlx.DB.
 type db struct {
-	id     uint
-	dbx    **sqlx.DB
-	tx     *sqlx.Tx
-	m      *sync.Mutex
-	logOut *log.Logger
-	logErr *log.Logger
-	err    error
+	id        uint
+	dbx       **sqlx.DB
+	closed    *bool
+	tx        txConn
+	txStmts   *stmtCache
+	attempt   int
+	savepoint string
+
+	hooks       *txHooks
+	parentHooks *txHooks
+	leak        *time.Timer
+	txCtx       context.Context
+	txCancel    context.CancelFunc
+	leakHook    TxLeakHook
+	replicas    *replicaSet
+	firstRow    func()
+	failover    *failover
+	m           *sync.Mutex
+	logOut      *log.Logger
+	logErr      *log.Logger
+	err         error
 
 	ctx      *ctx
 	env      Environment
@@ -41,12 +58,16 @@ func (conn *db) copy() *db {
 	return &db{
 		id:       conn.id,
 		dbx:      conn.dbx,
+		closed:   conn.closed,
 		m:        conn.m,
 		logOut:   conn.logOut,
 		logErr:   conn.logErr,
 		ctx:      conn.ctx,
 		env:      conn.env,
 		profiler: conn.profiler,
+		leakHook: conn.leakHook,
+		replicas: conn.replicas,
+		failover: conn.failover,
 	}
 }
 
@@ -71,6 +92,11 @@ func (conn *db) hasVerbose() bool {
 	return Verbose || conn.env.Verbose
 }
 
+// dialect returns the SQL dialect of the connection driver.
+func (conn *db) dialect() builder.Dialect {
+	return builder.GetDialect(conn.env.Driver)
+}
+
 // hasProfiling says if the connection have a profiler attached.
 func (conn *db) hasProfiling() bool {
 	return conn.profiler != nil
@@ -78,18 +104,23 @@ func (conn *db) hasProfiling() bool {
 
 // DB return the unwrapped sqlx.DB.
 func (conn *db) DB() *sqlx.DB {
-	conn.Connect()
-	return *conn.dbx
+	dbx, _ := conn.pool()
+	return dbx
 }
 
 // Close closes the database and prevents new queries from starting.
+// The connection is released from the registry, the connections sharing
+// its *sqlx.DB return ErrConnectionClosed after.
 func (conn *db) Close() (err error) {
 	if conn.ctx != nil {
 		conn.ctx.Done()
 	}
 
-	if dbx := *conn.dbx; dbx != nil {
-		err = dbx.Close()
+	unregisterConn(conn)
+	err = conn.closePool()
+
+	if conn.replicas != nil {
+		conn.replicas.close()
 	}
 	return
 }
@@ -97,14 +128,27 @@ func (conn *db) Close() (err error) {
 // Ping verifies a connection to the database is still alive,
 // establishing a connection if necessary.
 func (conn *db) Ping() error {
-	if err := conn.Connect(); err != nil {
+	return conn.PingContext(context.Background())
+}
+
+// PingContext is the context version of Ping.
+func (conn *db) PingContext(ctx context.Context) error {
+	dbx, err := conn.pool()
+	if err != nil {
 		return err
 	}
 
-	if dbx := (*conn.dbx); dbx != nil {
-		return dbx.Ping()
+	ctx, cancel := conn.withTimeout(ctx)
+	defer cancel()
+
+	err = conn.classify(dbx.PingContext(ctx))
+	if conn.failover != nil && errors.Is(err, ErrConnectionLost) {
+		// try the other hosts.
+		if dbx, err = conn.pool(); err == nil {
+			err = conn.classify(dbx.PingContext(ctx))
+		}
 	}
-	return driver.ErrBadConn
+	return err
 }
 
 // MustPing call Ping and panic if there is an error.
@@ -114,35 +158,72 @@ func (conn *db) MustPing() {
 	}
 }
 
+// load returns the *sqlx.DB of the connection, nil if not connected.
+// It is read under conn.m since the failover releases it on the loss of its host.
+func (conn *db) load() *sqlx.DB {
+	conn.m.Lock()
+	defer conn.m.Unlock()
+	return *conn.dbx
+}
+
+// pool connect to the database and returns its *sqlx.DB.
+func (conn *db) pool() (*sqlx.DB, error) {
+	if err := conn.Connect(); err != nil {
+		return nil, err
+	}
+
+	if dbx := conn.load(); dbx != nil {
+		return dbx, nil
+	}
+	return nil, driver.ErrBadConn
+}
+
+// closePool close the *sqlx.DB of the connection and mark it closed.
+func (conn *db) closePool() error {
+	conn.m.Lock()
+	dbx := *conn.dbx
+	*conn.dbx = nil
+	if conn.closed != nil {
+		*conn.closed = true
+	}
+	conn.m.Unlock()
+
+	if dbx == nil {
+		return nil
+	}
+
+	purgeStmtCache(dbx)
+	return dbx.Close()
+}
+
 // Connect to a database and verify with a ping.
+// ErrConnectionClosed is returned once the connection has been closed.
 func (conn *db) Connect() (err error) {
-	if (*conn.dbx) != nil {
-		return
+	conn.m.Lock()
+	if conn.closed != nil && *conn.closed {
+		conn.m.Unlock()
+		return ErrConnectionClosed
 	}
 
-	conn.m.Lock()
-	if (*conn.dbx) != nil {
+	if *conn.dbx != nil {
 		conn.m.Unlock()
 		return
 	}
 
-	*conn.dbx, err = sqlx.Connect(
-		conn.env.Driver,
-		conn.env.String(),
-	)
+	dbx, err := conn.connect()
+	*conn.dbx = dbx
 
 	conn.m.Unlock()
 	if err != nil {
 		return
 	}
 
-	dbx := *conn.dbx
 	dbx.SetMaxIdleConns(conn.env.MaxIdle)
 	dbx.SetMaxOpenConns(conn.env.MaxOpen)
 	dbx.SetConnMaxLifetime(conn.env.MaxLifetime)
 	dbx.SetConnMaxIdleTime(conn.env.MaxLifetime)
 
-	if conn.env.ProfilerEnable {
+	if conn.env.ProfilerEnable && conn.profiler == nil {
 		conn.m.Lock()
 		conn.ctx = newContext(nil)
 		conn.profiler = newProfiler(conn.env.ProfilerOutput)
@@ -162,11 +243,26 @@ func (conn *db) Connect() (err error) {
 
 // Tables fetch tables of the current schema
 func (conn *db) Tables() (t []string) {
-	if conn.env.Driver == "mysql" {
-		_, _ = conn.SelectSlice(builder.NewQuery("SHOW TABLES"), func(v []any) {
-			t = append(t, string(v[0].([]uint8)))
-		})
+	var q *builder.Query
+	switch conn.dialect() {
+	case builder.MySQL:
+		q = builder.NewQuery("SHOW TABLES")
+	case builder.PostgreSQL:
+		q = builder.NewQuery("SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = current_schema()")
+	case builder.SQLite:
+		q = builder.NewQuery("SELECT name FROM sqlite_master WHERE type = 'table'")
+	default:
+		return
 	}
+
+	_, _ = conn.SelectSlice(q, func(v []any) {
+		switch name := v[0].(type) {
+		case []uint8:
+			t = append(t, string(name))
+		case string:
+			t = append(t, name)
+		}
+	})
 	return t
 }
 
@@ -8,22 +8,28 @@ package database
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/kovacou/go-env"
+
+	"github.com/kovacou/go-database/builder"
 )
 
 // Default environment configuration.
 const (
-	defaultDriver      = "mysql"
-	defaultProtocol    = "tcp"
-	defaultCharset     = "utf8mb4"
-	defaultHost        = "172.18.0.1"
-	defaultPort        = "3306"
-	defaultMaxIdle     = 1
-	defaultMaxOpen     = 2
-	defaultMaxLifetime = 1800 * time.Second
+	defaultDriver       = "mysql"
+	defaultProtocol     = "tcp"
+	defaultCharset      = "utf8mb4"
+	defaultHost         = "172.18.0.1"
+	defaultPort         = "3306"
+	defaultPostgresPort = "5432"
+	defaultMaxIdle      = 1
+	defaultMaxOpen      = 2
+	defaultMaxLifetime  = 1800 * time.Second
+	defaultMaxPacket    = 4 << 20
+	defaultReplicaRetry = 30 * time.Second
 )
 
 // Environment store the configuration to open a new connection.
@@ -45,16 +51,39 @@ type Environment struct {
 	MaxOpen        int           `env:"DATABASE_MAXOPEN"`
 	MaxIdle        int           `env:"DATABASE_MAXIDLE"`
 	MaxLifetime    time.Duration `env:"DATABASE_MAXLIFETIME"`
+	MaxPacket      int           `env:"DATABASE_MAXPACKET"`
+	Timeout        time.Duration `env:"DATABASE_TIMEOUT"`
+	StmtCache      int           `env:"DATABASE_STMT_CACHE"`
+	TxLeakTimeout  time.Duration `env:"DATABASE_TX_LEAK_TIMEOUT"`
+	TxLeakRollback bool          `env:"DATABASE_TX_LEAK_ROLLBACK"`
 	ProfilerEnable bool          `env:"DATABASE_PROFILER_ENABLE"`
 	ProfilerOutput string        `env:"DATABASE_PROFILER_OUTPUT"`
 	Verbose        bool          `env:"DATABASE_VERBOSE"`
 	Debug          bool          `env:"DATABASE_DEBUG"`
 	ErrorNoRows    bool          `env:"DATABASE_ERROR_NOROWS"`
+	Quote          bool          `env:"DATABASE_QUOTE"`
+	Strict         bool          `env:"DATABASE_STRICT"`
+
+	// Replicas are the hosts ("host[:port]" or DSN) the reads are balanced on.
+	Replicas        []string
+	ReplicaBalancer string        `env:"DATABASE_REPLICA_BALANCER"`
+	ReplicaRetry    time.Duration `env:"DATABASE_REPLICA_RETRY"`
+
+	// Hosts are the hosts ("host[:port]" or DSN) tried in order by Connect, Host is ignored.
+	Hosts    []string
+	Failover string `env:"DATABASE_FAILOVER"`
 }
 
 // Boot load the default environment configuration.
 func (e *Environment) Boot() {
 	env.Unmarshal(e)
+	if v, ok := env.Lookup("DATABASE_REPLICAS"); ok {
+		e.Replicas = toList(v)
+	}
+
+	if v, ok := env.Lookup("DATABASE_HOSTS"); ok {
+		e.Hosts = toList(v)
+	}
 }
 
 // Load custom environment variable based on given alias.
@@ -63,7 +92,7 @@ func (e *Environment) Load(alias string) {
 		return
 	}
 
-	for _, key := range []string{"DSN", "DRIVER", "PROTOCOL", "HOST", "PORT", "USER", "PASS", "CHARSET", "SCHEMA", "MODE", "AUTOCONNECT", "MAXOPEN", "MAXIDLE", "MAXLIFETIME", "PARSETIME", "ERROR_NOROWS"} {
+	for _, key := range []string{"DSN", "DRIVER", "PROTOCOL", "HOST", "PORT", "USER", "PASS", "CHARSET", "SCHEMA", "MODE", "AUTOCONNECT", "MAXOPEN", "MAXIDLE", "MAXLIFETIME", "MAXPACKET", "TIMEOUT", "STMT_CACHE", "REPLICAS", "REPLICA_BALANCER", "REPLICA_RETRY", "HOSTS", "FAILOVER", "TX_LEAK_TIMEOUT", "TX_LEAK_ROLLBACK", "PARSETIME", "ERROR_NOROWS", "QUOTE", "STRICT"} {
 		if v, ok := env.Lookup(fmt.Sprintf("DATABASE_%s_%s", e.Alias, key)); ok {
 			switch key {
 			case "DSN":
@@ -96,12 +125,36 @@ func (e *Environment) Load(alias string) {
 				e.MaxIdle = toInt(v)
 			case "MAXLIFETIME":
 				e.MaxLifetime = toDuration(v)
+			case "MAXPACKET":
+				e.MaxPacket = toInt(v)
+			case "TIMEOUT":
+				e.Timeout = toDuration(v)
+			case "STMT_CACHE":
+				e.StmtCache = toInt(v)
+			case "REPLICAS":
+				e.Replicas = toList(v)
+			case "REPLICA_BALANCER":
+				e.ReplicaBalancer = v
+			case "REPLICA_RETRY":
+				e.ReplicaRetry = toDuration(v)
+			case "HOSTS":
+				e.Hosts = toList(v)
+			case "FAILOVER":
+				e.Failover = v
+			case "TX_LEAK_TIMEOUT":
+				e.TxLeakTimeout = toDuration(v)
+			case "TX_LEAK_ROLLBACK":
+				e.TxLeakRollback = toBool(v)
 			case "VERBOSE":
 				e.Verbose = toBool(v)
 			case "DEBUG":
 				e.Debug = toBool(v)
 			case "ERROR_NOROWS":
 				e.ErrorNoRows = toBool(v)
+			case "QUOTE":
+				e.Quote = toBool(v)
+			case "STRICT":
+				e.Strict = toBool(v)
 			}
 		}
 	}
@@ -121,12 +174,20 @@ func (e *Environment) Validate() error {
 	}
 
 	// Managing default values.
+	if e.Host == "" && len(e.Hosts) > 0 {
+		e.Host = withHost(*e, e.Hosts[0]).Host
+	}
+
 	if e.Driver == "" {
 		e.Driver = defaultDriver
 	}
 
 	if e.Port == "" {
-		e.Port = defaultPort
+		if builder.GetDialect(e.Driver) == builder.PostgreSQL {
+			e.Port = defaultPostgresPort
+		} else {
+			e.Port = defaultPort
+		}
 	}
 
 	if e.Protocol == "" {
@@ -157,6 +218,30 @@ func (e *Environment) Validate() error {
 		e.MaxLifetime = defaultMaxLifetime
 	}
 
+	if e.MaxPacket <= 0 {
+		e.MaxPacket = defaultMaxPacket
+	}
+
+	if e.ReplicaBalancer == "" {
+		e.ReplicaBalancer = ReplicaRoundRobin
+	}
+
+	if e.ReplicaBalancer != ReplicaRoundRobin && e.ReplicaBalancer != ReplicaLeastLatency {
+		return fmt.Errorf("unknown Environment.ReplicaBalancer %q", e.ReplicaBalancer)
+	}
+
+	if e.ReplicaRetry <= 0 {
+		e.ReplicaRetry = defaultReplicaRetry
+	}
+
+	if e.Failover == "" {
+		e.Failover = FailoverFirst
+	}
+
+	if e.Failover != FailoverFirst && e.Failover != FailoverNext {
+		return fmt.Errorf("unknown Environment.Failover %q", e.Failover)
+	}
+
 	if e.Alias == "" {
 		e.Alias = e.Schema
 	}
@@ -170,6 +255,17 @@ func (e *Environment) String() string {
 		return e.DSN
 	}
 
+	if builder.GetDialect(e.Driver) == builder.PostgreSQL {
+		return fmt.Sprintf(
+			"postgres://%s:%s@%s:%s/%s",
+			url.QueryEscape(e.User),
+			url.QueryEscape(e.Pass),
+			e.Host,
+			e.Port,
+			e.Schema,
+		)
+	}
+
 	dsn := fmt.Sprintf(
 		"%s:%s@%s(%s:%s)/%s?charset=%s",
 		e.User,
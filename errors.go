@@ -0,0 +1,194 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrDuplicateKey is returned when a unique constraint is violated.
+	ErrDuplicateKey = errors.New("database: duplicate key")
+
+	// ErrForeignKeyViolation is returned when a foreign key constraint is violated.
+	ErrForeignKeyViolation = errors.New("database: foreign key violation")
+
+	// ErrDeadlock is returned when a deadlock is detected.
+	ErrDeadlock = errors.New("database: deadlock")
+
+	// ErrLockWaitTimeout is returned when a lock cannot be acquired in time.
+	ErrLockWaitTimeout = errors.New("database: lock wait timeout")
+
+	// ErrSerialization is returned when a transaction cannot be serialized.
+	ErrSerialization = errors.New("database: serialization failure")
+
+	// ErrConnectionLost is returned when the connection to the server is lost.
+	ErrConnectionLost = errors.New("database: connection lost")
+
+	// ErrReadOnly is returned when writing on a read-only server or transaction.
+	ErrReadOnly = errors.New("database: read-only")
+
+	// ErrDataTooLong is returned when a value is too long for its column.
+	ErrDataTooLong = errors.New("database: data too long")
+)
+
+var (
+	// mysqlErrors map the MySQL error numbers to the errors of the package.
+	mysqlErrors = map[uint16]error{
+		1062: ErrDuplicateKey,
+		1586: ErrDuplicateKey,
+		1216: ErrForeignKeyViolation,
+		1217: ErrForeignKeyViolation,
+		1451: ErrForeignKeyViolation,
+		1452: ErrForeignKeyViolation,
+		1213: ErrDeadlock,
+		1205: ErrLockWaitTimeout,
+		1290: ErrReadOnly,
+		1792: ErrReadOnly,
+		1836: ErrReadOnly,
+		1406: ErrDataTooLong,
+		1927: ErrConnectionLost,
+		2006: ErrConnectionLost,
+		2013: ErrConnectionLost,
+	}
+
+	// sqlStateErrors map the SQLSTATE codes of PostgreSQL to the errors of the package.
+	sqlStateErrors = map[string]error{
+		"23505": ErrDuplicateKey,
+		"23503": ErrForeignKeyViolation,
+		"40P01": ErrDeadlock,
+		"55P03": ErrLockWaitTimeout,
+		"40001": ErrSerialization,
+		"25006": ErrReadOnly,
+		"22001": ErrDataTooLong,
+		"08000": ErrConnectionLost,
+		"08003": ErrConnectionLost,
+		"08006": ErrConnectionLost,
+		"57P01": ErrConnectionLost,
+	}
+
+	// mysqlKeyRegexp extract the key name of a MySQL error message.
+	mysqlKeyRegexp = regexp.MustCompile("for key '([^']+)'|CONSTRAINT `([^`]+)`")
+)
+
+// DriverError is a driver error classified into one of the errors of the package.
+// It matches its Kind with errors.Is and the driver error with errors.As.
+type DriverError struct {
+	// Kind is the error of the package (ErrDuplicateKey, ErrDeadlock...).
+	Kind error
+
+	// Code is the MySQL error number or the SQLSTATE code.
+	Code string
+
+	// Key is the name of the key or constraint violated, if any.
+	Key string
+
+	// Err is the driver error.
+	Err error
+}
+
+// Error returns the message of the driver error.
+func (e *DriverError) Error() string {
+	return e.Err.Error()
+}
+
+// Is says if target is the Kind of the error.
+func (e *DriverError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the driver error.
+func (e *DriverError) Unwrap() error {
+	return e.Err
+}
+
+// sqlStater is implemented by the errors of the PostgreSQL drivers (lib/pq & pgx).
+type sqlStater interface {
+	SQLState() string
+}
+
+// classifyError wrap err into a DriverError when it is a known driver error.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var de *DriverError
+	if errors.As(err, &de) {
+		return err
+	}
+
+	var me *mysql.MySQLError
+	if errors.As(err, &me) {
+		if kind, ok := mysqlErrors[me.Number]; ok {
+			out := &DriverError{
+				Kind: kind,
+				Code: strconv.Itoa(int(me.Number)),
+				Err:  err,
+			}
+
+			if m := mysqlKeyRegexp.FindStringSubmatch(me.Message); m != nil {
+				if out.Key = m[1]; out.Key == "" {
+					out.Key = m[2]
+				}
+			}
+			return out
+		}
+		return err
+	}
+
+	var ss sqlStater
+	if errors.As(err, &ss) {
+		if kind, ok := sqlStateErrors[ss.SQLState()]; ok {
+			return &DriverError{
+				Kind: kind,
+				Code: ss.SQLState(),
+				Key:  constraintOf(ss),
+				Err:  err,
+			}
+		}
+		return err
+	}
+
+	// the network errors are not classified, a dial or timeout error
+	// does not mean the connection is lost.
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return &DriverError{
+			Kind: ErrConnectionLost,
+			Err:  err,
+		}
+	}
+	return err
+}
+
+// isDialError says if err is a failure to dial the host, database/sql dials a new
+// connection once the pooled ones of a lost host are dropped.
+func isDialError(err error) bool {
+	var oe *net.OpError
+	return errors.As(err, &oe) && oe.Op == "dial"
+}
+
+// constraintOf returns the constraint name of a PostgreSQL error.
+func constraintOf(err any) string {
+	v := reflect.Indirect(reflect.ValueOf(err))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, name := range []string{"ConstraintName", "Constraint"} {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// pgError mimic the errors of the PostgreSQL drivers.
+type pgError struct {
+	Code           string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string    { return "pq: " + e.Code }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestUnexportedClassifyError(t *testing.T) {
+	assert.Nil(t, classifyError(nil))
+
+	{
+		err := classifyError(&mysql.MySQLError{
+			Number:  1062,
+			Message: "Duplicate entry 'foo@bar.com' for key 'users.email'",
+		})
+
+		var de *DriverError
+		assert.ErrorIs(t, err, ErrDuplicateKey)
+		assert.True(t, errors.As(err, &de))
+		assert.Equal(t, "1062", de.Code)
+		assert.Equal(t, "users.email", de.Key)
+
+		var me *mysql.MySQLError
+		assert.True(t, errors.As(err, &me))
+	}
+
+	{
+		err := classifyError(&mysql.MySQLError{
+			Number:  1452,
+			Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`articles`, CONSTRAINT `fk_author` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`))",
+		})
+
+		var de *DriverError
+		assert.ErrorIs(t, err, ErrForeignKeyViolation)
+		assert.True(t, errors.As(err, &de))
+		assert.Equal(t, "fk_author", de.Key)
+	}
+
+	for number, kind := range map[uint16]error{
+		1213: ErrDeadlock,
+		1205: ErrLockWaitTimeout,
+		1290: ErrReadOnly,
+		1406: ErrDataTooLong,
+	} {
+		assert.ErrorIs(t, classifyError(&mysql.MySQLError{Number: number}), kind)
+	}
+
+	for code, kind := range map[string]error{
+		"23505": ErrDuplicateKey,
+		"40P01": ErrDeadlock,
+		"40001": ErrSerialization,
+		"25006": ErrReadOnly,
+	} {
+		assert.ErrorIs(t, classifyError(fmt.Errorf("wrapped: %w", &pgError{Code: code})), kind)
+	}
+
+	{
+		var de *DriverError
+		err := classifyError(&pgError{Code: "23503", ConstraintName: "fk_author"})
+		assert.ErrorIs(t, err, ErrForeignKeyViolation)
+		assert.True(t, errors.As(err, &de))
+		assert.Equal(t, "fk_author", de.Key)
+	}
+
+	assert.ErrorIs(t, classifyError(driver.ErrBadConn), ErrConnectionLost)
+	assert.ErrorIs(t, classifyError(mysql.ErrInvalidConn), ErrConnectionLost)
+
+	other := errors.New("other")
+	assert.Equal(t, other, classifyError(other))
+
+	unknown := &mysql.MySQLError{Number: 1064}
+	assert.Equal(t, unknown, classifyError(unknown))
+}
+
+func TestUnexportedClassifyNetError(t *testing.T) {
+	err := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	assert.NotErrorIs(t, classifyError(err), ErrConnectionLost)
+	assert.NotErrorIs(t, classifyError(context.DeadlineExceeded), ErrConnectionLost)
+	assert.ErrorIs(t, classifyError(fmt.Errorf("read: %w", driver.ErrBadConn)), ErrConnectionLost)
+}
@@ -0,0 +1,132 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Failover policies of Environment.Hosts.
+const (
+	// FailoverFirst connects to the first available host, in order.
+	FailoverFirst = "first"
+
+	// FailoverNext connects to the first available host after the lost one.
+	FailoverNext = "next"
+)
+
+// failover manage the host of a connection with multiple hosts.
+type failover struct {
+	m       sync.Mutex
+	current int
+}
+
+// connect try the hosts in order according to the failover policy.
+func (f *failover) connect(conn *db) (dbx *sqlx.DB, err error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	hosts := conn.env.Hosts
+	start := 0
+	if conn.env.Failover == FailoverNext {
+		start = f.current
+	}
+
+	for i := range hosts {
+		n := (start + i) % len(hosts)
+		e := withHost(conn.env, hosts[n])
+
+		if dbx, err = sqlx.Connect(e.Driver, e.String()); err != nil {
+			if conn.hasVerbose() {
+				conn.logErr.Printf("host %s unavailable: %s", hosts[n], err.Error())
+			}
+			continue
+		}
+
+		if n != f.current && conn.hasVerbose() {
+			conn.logOut.Printf("switched from host %s to %s", hosts[f.current], hosts[n])
+		}
+
+		f.current = n
+		return
+	}
+	return
+}
+
+// lost release dbx after the loss of the connection to its host,
+// the next call to Connect will try the hosts again.
+// dbx is swapped under conn.m and closed after, the statements running on it fail
+// without affecting the ones using the next *sqlx.DB.
+func (f *failover) lost(conn *db, dbx *sqlx.DB) {
+	conn.m.Lock()
+	if *conn.dbx != dbx {
+		conn.m.Unlock()
+		return
+	}
+	*conn.dbx = nil
+	conn.m.Unlock()
+
+	f.m.Lock()
+	host := conn.env.Hosts[f.current]
+	if conn.env.Failover == FailoverNext {
+		f.current = (f.current + 1) % len(conn.env.Hosts)
+	}
+	f.m.Unlock()
+
+	if conn.hasVerbose() {
+		conn.logErr.Printf("connection to host %s lost", host)
+	}
+
+	purgeStmtCache(dbx)
+	_ = dbx.Close()
+}
+
+// withHost returns the environment e connecting to host.
+// The host is either a DSN or a "host[:port]" using the credentials of e.
+func withHost(e Environment, host string) Environment {
+	if strings.Contains(host, "@") || strings.Contains(host, "://") {
+		e.DSN = host
+		return e
+	}
+
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		e.Host, e.Port = h, p
+	} else {
+		e.Host = host
+	}
+	return e
+}
+
+// -------------------------------------------------
+
+// connect open the *sqlx.DB of the connection, on one of the hosts with failover.
+func (conn *db) connect() (*sqlx.DB, error) {
+	if conn.failover != nil {
+		return conn.failover.connect(conn)
+	}
+	return sqlx.Connect(conn.env.Driver, conn.env.String())
+}
+
+// classify the driver error, the connection is released on loss to fail over to another host.
+// With multiple hosts, a dial error is a loss of the current host.
+func (conn *db) classify(err error) error {
+	err = classifyError(err)
+	if conn.failover != nil && isDialError(err) && !errors.Is(err, ErrConnectionLost) {
+		err = &DriverError{Kind: ErrConnectionLost, Err: err}
+	}
+
+	if conn.failover != nil && !conn.IsTx() && errors.Is(err, ErrConnectionLost) {
+		if dbx := conn.load(); dbx != nil {
+			conn.failover.lost(conn, dbx)
+		}
+	}
+	return err
+}
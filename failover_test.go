@@ -0,0 +1,129 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+func TestUnexportedWithHost(t *testing.T) {
+	e := Environment{Host: "a", Port: "3306"}
+	assert.Equal(t, "b", withHost(e, "b").Host)
+	assert.Equal(t, "3307", withHost(e, "b:3307").Port)
+	assert.Equal(t, "u:p@tcp(b)/db", withHost(e, "u:p@tcp(b)/db").DSN)
+}
+
+func TestUnexportedFailover(t *testing.T) {
+	e := Environment{
+		Driver: "fake",
+		User:   "user",
+		Pass:   "pass",
+		Hosts:  []string{"down:3306", "up1:3306", "up2:3306"},
+	}
+	assert.NoError(t, e.Validate())
+	assert.Equal(t, "down", e.Host)
+	assert.Equal(t, FailoverFirst, e.Failover)
+
+	conn := &db{
+		env:      e,
+		m:        &sync.Mutex{},
+		dbx:      new(*sqlx.DB),
+		failover: &failover{},
+	}
+	defer conn.Close()
+
+	assert.NoError(t, conn.Connect())
+	assert.Equal(t, 1, conn.failover.current)
+
+	// the connection is released on loss.
+	lost := fmt.Errorf("read: %w", driver.ErrBadConn)
+	assert.ErrorIs(t, conn.classify(lost), ErrConnectionLost)
+	assert.Nil(t, *conn.dbx)
+
+	assert.NoError(t, conn.Connect())
+	assert.Equal(t, 1, conn.failover.current)
+
+	// with FailoverNext, the next host is tried first.
+	conn.env.Failover = FailoverNext
+	conn.classify(lost)
+	assert.NoError(t, conn.Connect())
+	assert.Equal(t, 2, conn.failover.current)
+
+	conn.classify(lost)
+	assert.NoError(t, conn.Connect())
+	assert.Equal(t, 1, conn.failover.current)
+}
+
+func TestUnexportedFailoverConcurrent(t *testing.T) {
+	conn := &db{
+		env:      Environment{Driver: "fake", Hosts: []string{"up1:3306", "up2:3306"}},
+		m:        &sync.Mutex{},
+		dbx:      new(*sqlx.DB),
+		failover: &failover{},
+	}
+	defer conn.Close()
+
+	// the statements run while the connection is released by the failover,
+	// the ones running on the released *sqlx.DB may fail but never race.
+	wg := sync.WaitGroup{}
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_, _ = conn.Exec(builder.NewQuery("DELETE FROM a"))
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		conn.classify(driver.ErrBadConn)
+		assert.NoError(t, conn.Connect())
+	}
+	wg.Wait()
+
+	_, err := conn.Exec(builder.NewQuery("DELETE FROM a"))
+	assert.NoError(t, err)
+}
+
+func TestUnexportedFailoverHostDown(t *testing.T) {
+	conn := &db{
+		env:      Environment{Driver: "fake", Hosts: []string{"lost1:3306", "up2:3306"}},
+		m:        &sync.Mutex{},
+		dbx:      new(*sqlx.DB),
+		failover: &failover{},
+	}
+	defer conn.Close()
+
+	_, err := conn.Exec(builder.NewQuery("DELETE FROM a"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, conn.failover.current)
+
+	// the host dies after the connection, the new connections fail to dial.
+	fakeDown("lost1")
+	_, err = conn.Exec(builder.NewQuery("DELETE FROM a"))
+	assert.ErrorIs(t, err, ErrConnectionLost)
+	assert.Nil(t, conn.load())
+
+	_, err = conn.Exec(builder.NewQuery("DELETE FROM a"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, conn.failover.current)
+
+	// without multiple hosts, a dial error is not a loss.
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	assert.NotErrorIs(t, (&db{}).classify(dial), ErrConnectionLost)
+	assert.ErrorIs(t, conn.Primary().(*db).classify(dial), ErrConnectionLost)
+}
@@ -0,0 +1,197 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// fakeCloses count the statements closed by the fake driver.
+	fakeCloses int32
+
+	// fakeQueries record the queries executed by the fake driver.
+	fakeQueries []string
+
+	// fakeResults are the results of the queries of the fake driver.
+	fakeResults map[string]fakeResult
+
+	// fakeDownHosts are the hosts going down after the connection.
+	fakeDownHosts []string
+
+	// fm is the mutex of fakeQueries.
+	fm sync.Mutex
+)
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+// newFakeDB create a new connection on the fake driver.
+func newFakeDB() *db {
+	fm.Lock()
+	fakeQueries = nil
+	fakeResults = map[string]fakeResult{}
+	fakeDownHosts = nil
+	fm.Unlock()
+
+	dbx := sqlx.MustOpen("fake", "")
+	return &db{
+		dbx: &dbx,
+		m:   &sync.Mutex{},
+	}
+}
+
+// fakeRecorded returns the queries recorded by the fake driver.
+func fakeRecorded() []string {
+	fm.Lock()
+	defer fm.Unlock()
+	return append([]string{}, fakeQueries...)
+}
+
+// fakeRows set the columns & rows returned by the fake driver for the query.
+func fakeRows(query string, cols []string, rows ...[]driver.Value) {
+	fm.Lock()
+	defer fm.Unlock()
+	fakeResults[query] = fakeResult{cols: cols, rows: rows}
+}
+
+// fakeDown make the host unreachable, its open connections are lost.
+func fakeDown(host string) {
+	fm.Lock()
+	defer fm.Unlock()
+	fakeDownHosts = append(fakeDownHosts, host)
+}
+
+// fakeIsDown says if the host of dsn is down.
+func fakeIsDown(dsn string) bool {
+	fm.Lock()
+	defer fm.Unlock()
+	for _, host := range fakeDownHosts {
+		if strings.Contains(dsn, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeRecord record a query of the fake driver.
+func fakeRecord(query string) {
+	fm.Lock()
+	defer fm.Unlock()
+	fakeQueries = append(fakeQueries, query)
+}
+
+type fakeDriver struct{}
+
+// Open a connection, the hosts containing "down" or set by fakeDown are unreachable.
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	if strings.Contains(dsn, "down") || fakeIsDown(dsn) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return fakeConn{dsn: dsn}, nil
+}
+
+type fakeConn struct {
+	dsn string
+}
+
+func (fakeConn) Close() error { return nil }
+
+// Prepare a statement, the connection is lost when its host is down.
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if fakeIsDown(c.dsn) {
+		return nil, driver.ErrBadConn
+	}
+	return fakeStmt{query: query}, nil
+}
+
+// Begin a transaction, the connection is lost when its host is down.
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx begin a transaction, the isolation level & read-only mode are recorded.
+func (c fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if fakeIsDown(c.dsn) {
+		return nil, driver.ErrBadConn
+	}
+
+	query := "BEGIN"
+	if level := sql.IsolationLevel(opts.Isolation); level != sql.LevelDefault {
+		query += " " + level.String()
+	}
+	if opts.ReadOnly {
+		query += " READ ONLY"
+	}
+
+	fakeRecord(query)
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { fakeRecord("COMMIT"); return nil }
+func (fakeTx) Rollback() error { fakeRecord("ROLLBACK"); return nil }
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error  { atomic.AddInt32(&fakeCloses, 1); return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+// Exec a statement, it fails when an argument is "fail".
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fakeRecord(s.query)
+	for _, arg := range args {
+		if arg == "fail" {
+			return nil, errors.New("exec failed")
+		}
+	}
+	return driver.ResultNoRows, nil
+}
+
+// Query returns the rows set by fakeRows.
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	fakeRecord(s.query)
+
+	fm.Lock()
+	defer fm.Unlock()
+	res, ok := fakeResults[s.query]
+	if !ok {
+		return nil, errors.New("no result for " + s.query)
+	}
+	return &res, nil
+}
+
+type fakeResult struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeResult) Columns() []string { return r.cols }
+func (r *fakeResult) Close() error      { return nil }
+
+func (r *fakeResult) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TxHook is a callback run after the end of a transaction.
+type TxHook func() error
+
+// HookError is returned by Commit & Rollback when some hooks failed.
+// The transaction itself has been committed or rolled back.
+type HookError struct {
+	Errs []error
+}
+
+// Error returns the messages of the errors of the hooks.
+func (e *HookError) Error() string {
+	msg := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msg[i] = err.Error()
+	}
+	return fmt.Sprintf("database: %d transaction hook(s) failed: %s", len(e.Errs), strings.Join(msg, "; "))
+}
+
+// txHooks store the hooks of a transaction.
+type txHooks struct {
+	m        sync.Mutex
+	commit   []TxHook
+	rollback []TxHook
+}
+
+// take returns the hooks and clear them.
+func (h *txHooks) take() (commit, rollback []TxHook) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	commit, rollback = h.commit, h.rollback
+	h.commit, h.rollback = nil, nil
+	return
+}
+
+// merge append the hooks into h.
+func (h *txHooks) merge(commit, rollback []TxHook) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.commit = append(h.commit, commit...)
+	h.rollback = append(h.rollback, rollback...)
+}
+
+// runHooks run the hooks in order and collect their errors.
+func runHooks(hooks []TxHook) error {
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &HookError{Errs: errs}
+	}
+	return nil
+}
+
+// AfterCommit register hooks run after the commit of the transaction, in registration order.
+// In a savepoint, the hooks are run after the commit of the outer transaction.
+// Outside a transaction, the hooks are run immediately.
+func (conn *db) AfterCommit(hooks ...TxHook) error {
+	if !conn.IsTx() {
+		return runHooks(hooks)
+	}
+
+	conn.hooks.merge(hooks, nil)
+	return nil
+}
+
+// AfterRollback register hooks run after the rollback of the transaction, in registration order.
+// Outside a transaction, the hooks are ignored.
+func (conn *db) AfterRollback(hooks ...TxHook) error {
+	if conn.IsTx() {
+		conn.hooks.merge(nil, hooks)
+	}
+	return nil
+}
+
+// afterCommit run the hooks after the commit of the transaction or the release of the savepoint.
+func (conn *db) afterCommit() error {
+	commit, rollback := conn.hooks.take()
+	if conn.IsSavepoint() {
+		conn.parentHooks.merge(commit, rollback)
+		return nil
+	}
+	return runHooks(commit)
+}
+
+// afterRollback run the hooks after the rollback of the transaction or the savepoint.
+func (conn *db) afterRollback() error {
+	_, rollback := conn.hooks.take()
+	return runHooks(rollback)
+}
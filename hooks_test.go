@@ -0,0 +1,93 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnexportedTxHooks(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	var calls []string
+	hook := func(name string) TxHook {
+		return func() error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	// Commit
+	{
+		calls = nil
+		err := conn.RunTx(IsolationLevel, func(tx Connection) error {
+			assert.NoError(t, tx.AfterCommit(hook("commit 1"), hook("commit 2")))
+			assert.NoError(t, tx.AfterRollback(hook("rollback")))
+
+			return tx.RunTx(IsolationLevel, func(nested Connection) error {
+				assert.NoError(t, nested.AfterCommit(hook("nested commit")))
+				assert.Empty(t, calls)
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"commit 1", "commit 2", "nested commit"}, calls)
+	}
+
+	// Rollback
+	{
+		calls = nil
+		errTx := errors.New("tx")
+		err := conn.RunTx(IsolationLevel, func(tx Connection) error {
+			assert.NoError(t, tx.AfterCommit(hook("commit")))
+			assert.NoError(t, tx.AfterRollback(hook("rollback")))
+			return errTx
+		})
+		assert.Equal(t, errTx, err)
+		assert.Equal(t, []string{"rollback"}, calls)
+	}
+
+	// Rollback of a savepoint
+	{
+		calls = nil
+		err := conn.RunTx(IsolationLevel, func(tx Connection) error {
+			_ = tx.RunTx(IsolationLevel, func(nested Connection) error {
+				assert.NoError(t, nested.AfterCommit(hook("nested commit")))
+				assert.NoError(t, nested.AfterRollback(hook("nested rollback")))
+				return errors.New("nested")
+			})
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"nested rollback"}, calls)
+	}
+
+	// Errors
+	{
+		tx, err := conn.Tx()
+		assert.NoError(t, err)
+
+		errHook := errors.New("hook")
+		assert.NoError(t, tx.AfterCommit(func() error { return errHook }, func() error { return nil }))
+
+		var he *HookError
+		err = tx.Commit()
+		assert.True(t, errors.As(err, &he))
+		assert.Equal(t, []error{errHook}, he.Errs)
+	}
+
+	// Outside a transaction
+	{
+		calls = nil
+		assert.NoError(t, conn.AfterCommit(hook("commit")))
+		assert.NoError(t, conn.AfterRollback(hook("rollback")))
+		assert.Equal(t, []string{"commit"}, calls)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+// ErrRowsClosed is returned when a closed Rows is used.
+var ErrRowsClosed = errors.New("database: rows are closed")
+
+// Rows is a cursor over the results of a statement.
+// The rows are fetched one by one, Close must be called to release the connection
+// unless Next returned false. A Rows is not safe for concurrent use.
+type Rows interface {
+	rowsSeq
+
+	// Next prepares the next row, it returns false at the end of the results or on error.
+	Next() bool
+
+	// Scan copies the columns of the current row into dest.
+	Scan(dest ...any) error
+
+	// Map returns the current row as a map, owned by the caller.
+	Map() (map[string]any, error)
+
+	// Slice returns the current row as a slice, owned by the caller.
+	Slice() ([]any, error)
+
+	// Row returns the current row with typed getters.
+	Row() (Row, error)
+
+	// Len returns the number of rows read so far.
+	Len() int
+
+	// Err returns the error encountered during the iteration.
+	Err() error
+
+	// Close the cursor, it is safe to call it multiple times.
+	Close() error
+}
+
+// Iterate run an SELECT query and returns a cursor over the results.
+func (conn *db) Iterate(stmt Stmt) (Rows, error) {
+	return conn.IterateContext(context.Background(), stmt)
+}
+
+// IterateContext is the context version of Iterate.
+// Environment.Timeout bounds the execution of the query, not the iteration of the cursor.
+func (conn *db) IterateContext(ctx context.Context, stmt Stmt) (Rows, error) {
+	if node := conn.replicaFor(stmt); node != nil {
+		return conn.iterateFrom(ctx, node, stmt)
+	}
+
+	if err := conn.Connect(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &rows{
+		conn:   conn,
+		stmt:   stmt,
+		cancel: cancel,
+	}
+
+	if conn.hasProfiling() {
+		r.start = time.Now()
+	}
+
+	var timer *time.Timer
+	if conn.env.Timeout > 0 {
+		timer = time.AfterFunc(conn.env.Timeout, cancel)
+	}
+
+	var err error
+	if r.stmtx, r.release, err = preparex(ctx, conn, stmt); err == nil {
+		r.rows, err = r.stmtx.QueryxContext(ctx, stmt.Args()...)
+	}
+
+	if timer != nil && !timer.Stop() {
+		// the cursor is closed by the cancellation of ctx.
+		err = context.DeadlineExceeded
+	}
+
+	if err != nil {
+		r.setErr(err)
+		r.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// QueryIterate run an SELECT query and returns a cursor over the results.
+func (conn *db) QueryIterate(query string, args ...any) (Rows, error) {
+	return conn.IterateContext(context.Background(), builder.NewQuery(query, args...))
+}
+
+// QueryIterateContext is the context version of QueryIterate.
+func (conn *db) QueryIterateContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return conn.IterateContext(ctx, builder.NewQuery(query, args...))
+}
+
+// rows is the implementation of Rows.
+type rows struct {
+	conn    *db
+	stmt    Stmt
+	stmtx   *sqlx.Stmt
+	release func() error
+	rows    *sqlx.Rows
+	cancel  context.CancelFunc
+	start   time.Time
+	index   map[string]int
+	n       int
+	err     error
+	closed  bool
+}
+
+// Next prepares the next row.
+func (r *rows) Next() bool {
+	if r.closed || r.err != nil {
+		return false
+	}
+
+	if !r.rows.Next() {
+		r.setErr(r.rows.Err())
+		r.Close()
+		return false
+	}
+
+	r.n++
+	return true
+}
+
+// Scan copies the columns of the current row into dest.
+func (r *rows) Scan(dest ...any) error {
+	if r.closed {
+		return ErrRowsClosed
+	}
+	return r.setErr(r.rows.Scan(dest...))
+}
+
+// Map returns the current row as a map.
+func (r *rows) Map() (map[string]any, error) {
+	if r.closed {
+		return nil, ErrRowsClosed
+	}
+
+	out := map[string]any{}
+	if err := r.setErr(r.rows.MapScan(out)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Slice returns the current row as a slice.
+func (r *rows) Slice() ([]any, error) {
+	if r.closed {
+		return nil, ErrRowsClosed
+	}
+
+	out, err := r.rows.SliceScan()
+	return out, r.setErr(err)
+}
+
+// Row returns the current row with typed getters.
+func (r *rows) Row() (Row, error) {
+	if r.closed {
+		return Row{}, ErrRowsClosed
+	}
+
+	if r.index == nil {
+		cols, err := r.rows.Columns()
+		if err != nil {
+			return Row{}, r.setErr(err)
+		}
+
+		r.index = make(map[string]int, len(cols))
+		for i, col := range cols {
+			r.index[col] = i
+		}
+	}
+
+	values, err := r.rows.SliceScan()
+	if err != nil {
+		return Row{}, r.setErr(err)
+	}
+	return newRow(r.index, values), nil
+}
+
+// Len returns the number of rows read so far.
+func (r *rows) Len() int {
+	return r.n
+}
+
+// Err returns the error encountered during the iteration.
+func (r *rows) Err() error {
+	return r.err
+}
+
+// Close the cursor and profile the statement.
+func (r *rows) Close() (err error) {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	if r.rows != nil {
+		err = r.rows.Close()
+	}
+
+	if r.release != nil {
+		if errStmt := r.release(); err == nil {
+			err = errStmt
+		}
+	}
+
+	r.cancel()
+
+	if r.err != nil && r.conn.hasVerbose() {
+		r.conn.logErr.Println(r.err.Error())
+	}
+
+	r.conn.profilingStmt(r.stmt, r.err, r.start)
+	return
+}
+
+// setErr keep the first error of the iteration and returns it classified.
+func (r *rows) setErr(err error) error {
+	err = r.conn.classify(err)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return err
+}
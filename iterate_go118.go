@@ -0,0 +1,11 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.23
+
+package database
+
+// rowsSeq is empty, the range-over-func form of Rows requires go1.23.
+type rowsSeq interface{}
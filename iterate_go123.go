@@ -0,0 +1,33 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package database
+
+import "iter"
+
+// rowsSeq is the range-over-func form of Rows.
+type rowsSeq interface {
+	// All returns an iterator over the rows, the cursor is closed at the end of the loop.
+	All() iter.Seq2[Row, error]
+}
+
+// All returns an iterator over the rows.
+func (r *rows) All() iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		defer r.Close()
+		for r.Next() {
+			row, err := r.Row()
+			if !yield(row, err) || err != nil {
+				return
+			}
+		}
+
+		if err := r.Err(); err != nil {
+			yield(Row{}, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnexportedRowsAll(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	query := "SELECT id FROM articles"
+	fakeRows(query, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)}, []driver.Value{int64(3)})
+
+	rows, err := conn.QueryIterate(query)
+	assert.NoError(t, err)
+
+	var ids []int64
+	for row, err := range rows.All() {
+		assert.NoError(t, err)
+
+		id, err := row.Int64("id")
+		assert.NoError(t, err)
+		if ids = append(ids, id); id == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int64{1, 2}, ids)
+
+	// the cursor is closed at the end of the loop.
+	_, err = rows.Row()
+	assert.True(t, errors.Is(err, ErrRowsClosed))
+}
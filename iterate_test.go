@@ -0,0 +1,84 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+func TestUnexportedRowsClosed(t *testing.T) {
+	r := &rows{closed: true}
+	assert.False(t, r.Next())
+	assert.ErrorIs(t, r.Scan(), ErrRowsClosed)
+
+	_, err := r.Map()
+	assert.ErrorIs(t, err, ErrRowsClosed)
+
+	_, err = r.Slice()
+	assert.ErrorIs(t, err, ErrRowsClosed)
+
+	_, err = r.Row()
+	assert.ErrorIs(t, err, ErrRowsClosed)
+	assert.NoError(t, r.Close())
+}
+
+func TestIterate(t *testing.T) {
+	if !TestMysql {
+		return
+	}
+
+	conn, err := OpenEnv("DSN")
+	assert.NoError(t, err)
+
+	rows, err := conn.Iterate(&builder.Select{
+		Table:   "articles",
+		Columns: builder.ParseColumns("id"),
+	})
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint64
+		assert.NoError(t, rows.Scan(&id))
+	}
+	assert.NoError(t, rows.Err())
+}
+
+func TestUnexportedIterate(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+	conn.env.Timeout = 20 * time.Millisecond
+
+	query := "SELECT id FROM articles"
+	fakeRows(query, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)}, []driver.Value{int64(3)})
+
+	rows, err := conn.QueryIterate(query)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	// the timeout bounds the query, not the iteration.
+	var ids []int64
+	for rows.Next() {
+		time.Sleep(15 * time.Millisecond)
+
+		var id int64
+		assert.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.Equal(t, 3, rows.Len())
+	assert.ErrorIs(t, rows.Scan(), ErrRowsClosed)
+
+	_, err = conn.QueryIterate("SELECT unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+// TxLeak is the report of a transaction open longer than Environment.TxLeakTimeout.
+type TxLeak struct {
+	Alias      string
+	Start      time.Time
+	Duration   time.Duration
+	Stack      []byte
+	RolledBack bool
+}
+
+// TxLeakHook is called when a leaked transaction is detected.
+type TxLeakHook func(TxLeak)
+
+// SetTxLeakHook set the hook called when a leaked transaction is detected,
+// the leaks are logged when no hook is set.
+func (conn *db) SetTxLeakHook(hook TxLeakHook) {
+	conn.leakHook = hook
+}
+
+// watchLeak start the leak detector of the transaction.
+func (conn *db) watchLeak() {
+	if conn.env.TxLeakTimeout <= 0 {
+		return
+	}
+
+	start := time.Now()
+	stack := debug.Stack()
+	conn.leak = time.AfterFunc(conn.env.TxLeakTimeout, func() {
+		report := TxLeak{
+			Alias:    conn.env.Alias,
+			Start:    start,
+			Duration: time.Since(start),
+			Stack:    stack,
+		}
+
+		if conn.env.TxLeakRollback {
+			conn.closeTxStmts()
+			report.RolledBack = conn.tx.Rollback() == nil
+			if report.RolledBack {
+				_ = conn.afterRollback()
+			}
+		}
+
+		conn.reportLeak(report)
+	})
+}
+
+// unwatchLeak stop the leak detector of the transaction.
+func (conn *db) unwatchLeak() {
+	if conn.leak != nil {
+		conn.leak.Stop()
+	}
+}
+
+// reportLeak call the leak hook or log the leak.
+func (conn *db) reportLeak(report TxLeak) {
+	if conn.leakHook != nil {
+		conn.leakHook(report)
+		return
+	}
+
+	logger := conn.logErr
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	logger.Printf(
+		"transaction open for %s (rolled back: %t), started at:\n%s",
+		report.Duration.String(),
+		report.RolledBack,
+		report.Stack,
+	)
+}
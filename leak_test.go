@@ -0,0 +1,55 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnexportedTxLeak(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	conn.env.TxLeakTimeout = 10 * time.Millisecond
+	conn.env.TxLeakRollback = true
+
+	leaks := make(chan TxLeak, 1)
+	conn.SetTxLeakHook(func(leak TxLeak) {
+		leaks <- leak
+	})
+
+	// Leaked transaction
+	{
+		_, err := conn.Tx()
+		assert.NoError(t, err)
+
+		select {
+		case leak := <-leaks:
+			assert.True(t, leak.RolledBack)
+			assert.GreaterOrEqual(t, leak.Duration, 10*time.Millisecond)
+			assert.Contains(t, string(leak.Stack), "TestUnexportedTxLeak")
+		case <-time.After(time.Second):
+			t.Fatal("leak not detected")
+		}
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fakeRecorded())
+	}
+
+	// Committed transaction
+	{
+		tx, err := conn.Tx()
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		select {
+		case <-leaks:
+			t.Fatal("unexpected leak")
+		case <-time.After(30 * time.Millisecond):
+		}
+	}
+}
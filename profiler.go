@@ -53,7 +53,16 @@ func (p *profiler) Push(qs QueryState) {
 		qs.Runtime().String(),
 	)
 
-	p.write(filename, qs.Bytes())
+	body := qs.Bytes()
+	if n := QueryAttempt(qs); n > 0 {
+		body = append(body, fmt.Sprintf("\n-- attempt: %d\n", n)...)
+	}
+
+	if err := qs.Err(); err != nil {
+		body = append(body, fmt.Sprintf("\n-- error: %s\n", err.Error())...)
+	}
+
+	p.write(filename, body)
 	p.i++
 }
 
@@ -0,0 +1,251 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrUnknownAlias is returned when no connection is registered with an alias.
+	ErrUnknownAlias = errors.New("database: unknown alias")
+
+	// ErrConnectionClosed is returned by the connections whose *sqlx.DB has been closed
+	// by Close or CloseAlias.
+	ErrConnectionClosed = errors.New("database: connection closed")
+)
+
+var (
+	// m is the mutex that manage the pool of sqlx wrapper.
+	m sync.Mutex
+
+	// pool of sqlx wrapper by id.
+	cp = make(map[uint]*poolEntry, 5)
+
+	// map of the connections opened once by alias.
+	cm = make(map[string]uint, 5)
+
+	// cid is the id of the next connection of the pool.
+	cid uint
+)
+
+// PoolStats is the statistics of a connection of the registry.
+type PoolStats struct {
+	ID        uint
+	Alias     string
+	Connected bool
+	Replicas  int
+	sql.DBStats
+}
+
+// poolEntry is a connection of the pool "cp".
+type poolEntry struct {
+	id    uint
+	alias string
+	dbx   **sqlx.DB
+	conn  *db
+}
+
+// load returns the *sqlx.DB of the entry.
+func (e *poolEntry) load() *sqlx.DB {
+	e.conn.m.Lock()
+	defer e.conn.m.Unlock()
+	return *e.dbx
+}
+
+// close the *sqlx.DB & the replicas of the entry.
+func (e *poolEntry) close() (err error) {
+	err = e.conn.closePool()
+
+	if e.conn.replicas != nil {
+		e.conn.replicas.close()
+	}
+	return
+}
+
+// Get returns the connection registered with the alias.
+// The connection opened once is returned first, then the last one opened.
+func Get(alias string) (Connection, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if e := lookup(resolveAlias(alias)); e != nil {
+		return e.conn, nil
+	}
+	return nil, ErrUnknownAlias
+}
+
+// Aliases returns the sorted aliases of the registered connections.
+func Aliases() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	seen := make(map[string]bool, len(cp))
+	out := make([]string, 0, len(cp))
+	for _, e := range cp {
+		if !seen[e.alias] {
+			seen[e.alias] = true
+			out = append(out, e.alias)
+		}
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// CloseAlias closes and releases the connections registered with the alias (looked up like Get).
+// The connections of the alias already returned return ErrConnectionClosed after.
+func CloseAlias(alias string) (err error) {
+	m.Lock()
+	alias = resolveAlias(alias)
+	entries := make([]*poolEntry, 0, 1)
+	for _, e := range cp {
+		if e.alias == alias {
+			entries = append(entries, e)
+			unregister(e)
+		}
+	}
+	m.Unlock()
+
+	if len(entries) == 0 {
+		return ErrUnknownAlias
+	}
+
+	for _, e := range entries {
+		if errClose := e.close(); err == nil {
+			err = errClose
+		}
+	}
+	return
+}
+
+// Stats returns the statistics of the registered connections sorted by id.
+func Stats() []PoolStats {
+	m.Lock()
+	defer m.Unlock()
+
+	out := make([]PoolStats, 0, len(cp))
+	for _, e := range cp {
+		s := PoolStats{
+			ID:    e.id,
+			Alias: e.alias,
+		}
+
+		if dbx := e.load(); dbx != nil {
+			s.Connected = true
+			s.DBStats = dbx.Stats()
+		}
+
+		if e.conn.replicas != nil {
+			s.Replicas = len(e.conn.replicas.nodes)
+		}
+		out = append(out, s)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// -------------------------------------------------
+
+// lookup returns the entry of the alias, m must be locked.
+func lookup(alias string) (out *poolEntry) {
+	if id, ok := cm[alias]; ok {
+		return cp[id]
+	}
+
+	for _, e := range cp {
+		if e.alias == alias && (out == nil || e.id > out.id) {
+			out = e
+		}
+	}
+	return
+}
+
+// resolveAlias returns the registered alias matching alias as is or upper-cased
+// (like Environment.Load), m must be locked.
+func resolveAlias(alias string) string {
+	if lookup(alias) == nil {
+		if upper := strings.ToUpper(alias); lookup(upper) != nil {
+			return upper
+		}
+	}
+	return alias
+}
+
+// register conn in the pool "cp", with dbx if not nil.
+// Once, the connection shares the *sqlx.DB of the alias if it exists.
+func register(conn *db, once bool, dbx *sqlx.DB) {
+	m.Lock()
+	defer m.Unlock()
+
+	if once {
+		if id, ok := cm[conn.env.Alias]; ok {
+			if e, ok := cp[id]; ok {
+				// the mutex & the failover manage the shared *sqlx.DB.
+				conn.id, conn.dbx, conn.m, conn.closed = e.id, e.dbx, e.conn.m, e.conn.closed
+				conn.failover = e.conn.failover
+				return
+			}
+		}
+	}
+
+	conn.id, conn.dbx, conn.closed = cid, new(*sqlx.DB), new(bool)
+	*conn.dbx = dbx
+	cid++
+
+	cp[conn.id] = &poolEntry{
+		id:    conn.id,
+		alias: conn.env.Alias,
+		dbx:   conn.dbx,
+		conn:  conn,
+	}
+
+	if once {
+		cm[conn.env.Alias] = conn.id
+	}
+}
+
+// unregister release the entry, m must be locked.
+func unregister(e *poolEntry) {
+	delete(cp, e.id)
+	if id, ok := cm[e.alias]; ok && id == e.id {
+		delete(cm, e.alias)
+		delete(replicaSets, e.alias)
+	}
+}
+
+// unregisterConn release the entry of conn if it is registered.
+func unregisterConn(conn *db) {
+	m.Lock()
+	defer m.Unlock()
+
+	if e, ok := cp[conn.id]; ok && e.dbx == conn.dbx {
+		unregister(e)
+	}
+}
+
+// closeAll closes and releases all the connections of the pool.
+func closeAll() {
+	m.Lock()
+	entries := cp
+	cp = make(map[uint]*poolEntry, 5)
+	cm = make(map[string]uint, 5)
+	replicaSets = map[string]*replicaSet{}
+	m.Unlock()
+
+	for _, e := range entries {
+		_ = e.close()
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	e := Environment{
+		Driver: "fake",
+		User:   "user",
+		Pass:   "pass",
+	}
+
+	e.Alias = "TENANT_A"
+	a1, err := OpenOnceEnviron(e)
+	assert.NoError(t, err)
+
+	a2, err := OpenOnceEnviron(e)
+	assert.NoError(t, err)
+	assert.Same(t, a1.(*db).dbx, a2.(*db).dbx)
+
+	// the failover of the hosts is shared like the *sqlx.DB.
+	{
+		f := e
+		f.Alias, f.Hosts = "TENANT_F", []string{"f1:3306", "f2:3306"}
+		f1, err := OpenOnceEnviron(f)
+		assert.NoError(t, err)
+		f2, err := OpenOnceEnviron(f)
+		assert.NoError(t, err)
+
+		assert.NotNil(t, f1.(*db).failover)
+		assert.Same(t, f1.(*db).failover, f2.(*db).failover)
+		assert.NoError(t, CloseAlias("TENANT_F"))
+	}
+
+	e.Alias = "TENANT_B"
+	b, err := OpenEnviron(e)
+	assert.NoError(t, err)
+	assert.NoError(t, b.Ping())
+
+	// Get
+	{
+		conn, err := Get("TENANT_A")
+		assert.NoError(t, err)
+		assert.Same(t, a1, conn)
+
+		conn, err = Get("tenant_b")
+		assert.NoError(t, err)
+		assert.Same(t, b, conn)
+
+		_, err = Get("TENANT_C")
+		assert.ErrorIs(t, err, ErrUnknownAlias)
+	}
+
+	assert.Subset(t, Aliases(), []string{"TENANT_A", "TENANT_B"})
+
+	// Stats
+	{
+		stats := map[string]PoolStats{}
+		for _, s := range Stats() {
+			stats[s.Alias] = s
+		}
+		assert.False(t, stats["TENANT_A"].Connected)
+		assert.True(t, stats["TENANT_B"].Connected)
+		assert.Equal(t, 1, stats["TENANT_B"].OpenConnections)
+	}
+
+	// CloseAlias
+	{
+		assert.NoError(t, CloseAlias("tenant_b"))
+		assert.ErrorIs(t, CloseAlias("TENANT_B"), ErrUnknownAlias)
+		assert.ErrorIs(t, CloseAlias("tenant_b"), ErrUnknownAlias)
+		assert.NotContains(t, Aliases(), "TENANT_B")
+		assert.ErrorIs(t, b.Ping(), ErrConnectionClosed)
+		assert.ErrorIs(t, b.Copy().Connect(), ErrConnectionClosed)
+
+		_, err := b.QueryIterate("SELECT 1")
+		assert.ErrorIs(t, err, ErrConnectionClosed)
+		assert.Nil(t, b.DB())
+	}
+
+	// Close
+	{
+		assert.NoError(t, a1.Close())
+		_, err := Get("TENANT_A")
+		assert.ErrorIs(t, err, ErrUnknownAlias)
+		assert.ErrorIs(t, a2.Ping(), ErrConnectionClosed)
+
+		a3, err := OpenOnceEnviron(Environment{Driver: "fake", User: "user", Pass: "pass", Alias: "TENANT_A"})
+		assert.NoError(t, err)
+		assert.NotSame(t, a1.(*db).dbx, a3.(*db).dbx)
+		assert.NoError(t, a3.Close())
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+// Balancing strategies of the replicas.
+const (
+	ReplicaRoundRobin   = "round-robin"
+	ReplicaLeastLatency = "least-latency"
+)
+
+// replicaSets store the replicas of the connections opened once, by alias.
+var replicaSets = map[string]*replicaSet{}
+
+// replica is a read-only node of a replicaSet.
+type replica struct {
+	conn      *db
+	latency   time.Duration
+	downUntil time.Time
+}
+
+// replicaSet balance the reads between replicas.
+type replicaSet struct {
+	m        sync.Mutex
+	nodes    []*replica
+	next     uint32
+	balancer string
+	retry    time.Duration
+}
+
+// newReplicaSet create the replicas of the primary connection conn.
+func newReplicaSet(conn *db) *replicaSet {
+	rs := &replicaSet{
+		balancer: conn.env.ReplicaBalancer,
+		retry:    conn.env.ReplicaRetry,
+	}
+
+	for _, host := range conn.env.Replicas {
+		node := &db{
+			env:    replicaEnv(conn.env, host),
+			m:      &sync.Mutex{},
+			closed: new(bool),
+			logOut: conn.logOut,
+			logErr: conn.logErr,
+		}
+
+		node.dbx = new(*sqlx.DB)
+		rs.nodes = append(rs.nodes, &replica{conn: node})
+	}
+	return rs
+}
+
+// replicaSetOf returns the replicas of conn, shared by alias when opened once.
+func replicaSetOf(conn *db, once bool) *replicaSet {
+	if !once {
+		return newReplicaSet(conn)
+	}
+
+	m.Lock()
+	rs, ok := replicaSets[conn.env.Alias]
+	m.Unlock()
+	if ok {
+		return rs
+	}
+
+	rs = newReplicaSet(conn)
+	m.Lock()
+	defer m.Unlock()
+	if existing, ok := replicaSets[conn.env.Alias]; ok {
+		return existing
+	}
+
+	replicaSets[conn.env.Alias] = rs
+	return rs
+}
+
+// replicaEnv returns the environment of the replica host based on the primary one.
+// The host is either a DSN or a "host[:port]" using the credentials of the primary.
+func replicaEnv(e Environment, host string) Environment {
+	e.Replicas = nil
+	e.Alias += "_REPLICA"
+	e.Autoconnect = false
+	e.ProfilerEnable = false
+	e.Hosts = nil
+	return withHost(e, host)
+}
+
+// pick returns the next healthy replica according to the balancer.
+func (rs *replicaSet) pick(skip map[*replica]bool) *replica {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	now := time.Now()
+	healthy := make([]*replica, 0, len(rs.nodes))
+	for _, node := range rs.nodes {
+		if !skip[node] && !now.Before(node.downUntil) {
+			healthy = append(healthy, node)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	if rs.balancer == ReplicaLeastLatency {
+		best := healthy[0]
+		for _, node := range healthy[1:] {
+			if node.latency < best.latency {
+				best = node
+			}
+		}
+		return best
+	}
+
+	return healthy[int(atomic.AddUint32(&rs.next, 1)-1)%len(healthy)]
+}
+
+// eject the replica until the retry delay is elapsed.
+func (rs *replicaSet) eject(node *replica, err error) {
+	rs.m.Lock()
+	node.downUntil = time.Now().Add(rs.retry)
+	rs.m.Unlock()
+
+	if conn := node.conn; conn.hasVerbose() {
+		conn.logErr.Printf("replica %s ejected for %s: %s", conn.env.Host, rs.retry.String(), err.Error())
+	}
+}
+
+// report the result of a read on the replica, it returns true if the replica has been ejected.
+// The replica is ejected when its connection is lost or when a new one fails to dial it.
+func (rs *replicaSet) report(node *replica, err error, latency time.Duration) bool {
+	if errors.Is(err, ErrConnectionLost) || isDialError(err) {
+		rs.eject(node, err)
+		return true
+	}
+
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	if node.latency == 0 {
+		node.latency = latency
+	} else {
+		node.latency = (4*node.latency + latency) / 5
+	}
+	return false
+}
+
+// close the replicas.
+func (rs *replicaSet) close() {
+	for _, node := range rs.nodes {
+		_ = node.conn.closePool()
+	}
+}
+
+// -------------------------------------------------
+
+// markFirstRow report the first row (or the end of the rows) of a read on a replica.
+func (conn *db) markFirstRow() {
+	if conn.firstRow != nil {
+		conn.firstRow()
+		conn.firstRow = nil
+	}
+}
+
+// Primary returns a copy of the connection reading from the primary only.
+func (conn *db) Primary() Connection {
+	return conn.primary()
+}
+
+// primary returns a copy of the connection without replicas.
+func (conn *db) primary() *db {
+	connPrimary := conn.copy()
+	connPrimary.replicas = nil
+	return connPrimary
+}
+
+// replicaFor returns a connected replica to read stmt, or nil to read from the primary.
+func (conn *db) replicaFor(stmt Stmt) *replica {
+	if conn.replicas == nil || conn.IsTx() {
+		return nil
+	}
+
+	if l, ok := stmt.(builder.Locker); ok && l.IsLocked() {
+		return nil
+	}
+
+	skip := map[*replica]bool{}
+	for {
+		node := conn.replicas.pick(skip)
+		if node == nil {
+			return nil
+		}
+
+		if err := node.conn.Connect(); err != nil {
+			conn.replicas.eject(node, err)
+			skip[node] = true
+			continue
+		}
+		return node
+	}
+}
+
+// onReplica returns the connection of the replica sharing the context & profiler of conn.
+func (conn *db) onReplica(node *replica) *db {
+	connReplica := node.conn.copy()
+	connReplica.ctx = conn.ctx
+	connReplica.profiler = conn.profiler
+	return connReplica
+}
+
+// readFrom run fn on the replica, the read is retried on the primary if the replica is lost
+// before any row was returned.
+// The latency of the replica is measured up to the first row, without the mapping of the rows.
+func (conn *db) readFrom(node *replica, fn func(*db) (int, error)) (int, error) {
+	var (
+		start   = time.Now()
+		latency time.Duration
+		c       = conn.onReplica(node)
+	)
+
+	c.firstRow = func() {
+		latency = time.Since(start)
+	}
+
+	n, err := fn(c)
+	if latency == 0 {
+		latency = time.Since(start)
+	}
+
+	if conn.replicas.report(node, err, latency) && n == 0 {
+		return fn(conn.primary())
+	}
+	return n, err
+}
+
+// iterateFrom open a Rows on the replica, retried on the primary if the replica is lost.
+func (conn *db) iterateFrom(ctx context.Context, node *replica, stmt Stmt) (Rows, error) {
+	start := time.Now()
+	rows, err := conn.onReplica(node).IterateContext(ctx, stmt)
+	if conn.replicas.report(node, err, time.Since(start)) {
+		return conn.primary().IterateContext(ctx, stmt)
+	}
+	return rows, err
+}
@@ -0,0 +1,130 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+func TestUnexportedReplicaEnv(t *testing.T) {
+	e := Environment{
+		Alias:    "MAIN",
+		Host:     "primary",
+		Port:     "3306",
+		Replicas: []string{"a"},
+	}
+
+	{
+		r := replicaEnv(e, "replica:3307")
+		assert.Equal(t, "replica", r.Host)
+		assert.Equal(t, "3307", r.Port)
+		assert.Equal(t, "MAIN_REPLICA", r.Alias)
+		assert.Nil(t, r.Replicas)
+	}
+
+	{
+		r := replicaEnv(e, "replica")
+		assert.Equal(t, "replica", r.Host)
+		assert.Equal(t, "3306", r.Port)
+	}
+
+	{
+		r := replicaEnv(e, "user:pass@tcp(replica:3306)/db")
+		assert.Equal(t, "user:pass@tcp(replica:3306)/db", r.DSN)
+	}
+}
+
+func TestUnexportedReplicaSet(t *testing.T) {
+	a, b := &replica{conn: &db{}}, &replica{conn: &db{}}
+	rs := &replicaSet{
+		nodes:    []*replica{a, b},
+		balancer: ReplicaRoundRobin,
+		retry:    time.Minute,
+	}
+
+	assert.Same(t, a, rs.pick(nil))
+	assert.Same(t, b, rs.pick(nil))
+	assert.Same(t, a, rs.pick(nil))
+	assert.Same(t, b, rs.pick(map[*replica]bool{a: true}))
+
+	rs.balancer = ReplicaLeastLatency
+	assert.False(t, rs.report(a, nil, 10*time.Millisecond))
+	assert.False(t, rs.report(b, nil, 5*time.Millisecond))
+	assert.Same(t, b, rs.pick(nil))
+
+	assert.True(t, rs.report(b, &DriverError{Kind: ErrConnectionLost, Err: errors.New("lost")}, 0))
+	assert.Same(t, a, rs.pick(nil))
+
+	rs.eject(a, errors.New("down"))
+	assert.Nil(t, rs.pick(nil))
+}
+
+func TestUnexportedReplicaFor(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	dbx := sqlx.MustOpen("fake", "")
+	node := &replica{conn: &db{dbx: &dbx, m: &sync.Mutex{}}}
+	conn.replicas = &replicaSet{
+		nodes: []*replica{node},
+		retry: time.Minute,
+	}
+
+	assert.Same(t, node, conn.replicaFor(&builder.Select{Table: "users"}))
+	assert.Nil(t, conn.replicaFor(&builder.Select{Table: "users", Lock: builder.ForUpdate()}))
+	assert.Nil(t, conn.Primary().(*db).replicaFor(&builder.Select{Table: "users"}))
+
+	tx, err := conn.Tx()
+	assert.NoError(t, err)
+	defer tx.Rollback()
+	assert.Nil(t, tx.(*db).replicaFor(&builder.Select{Table: "users"}))
+}
+
+func TestUnexportedReplicaDown(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	node := &replica{conn: &db{
+		env:    Environment{Driver: "fake", Host: "replica1", Port: "3306"},
+		dbx:    new(*sqlx.DB),
+		m:      &sync.Mutex{},
+		closed: new(bool),
+	}}
+	conn.replicas = &replicaSet{
+		nodes: []*replica{node},
+		retry: time.Minute,
+	}
+	defer conn.replicas.close()
+
+	fakeRows(" SELECT * FROM users", []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)})
+
+	// the latency is measured up to the first row, without the mapper.
+	n, err := conn.SelectMap(&builder.Select{Table: "users"}, func(map[string]any) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NotZero(t, node.latency)
+	assert.Less(t, node.latency, 20*time.Millisecond)
+	assert.True(t, node.downUntil.IsZero())
+
+	// the replica dies after the connection, the read is retried on the primary.
+	fakeDown("replica1")
+	n, err = conn.SelectMap(&builder.Select{Table: "users"}, func(map[string]any) {})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.True(t, node.downUntil.After(time.Now()))
+	assert.Nil(t, conn.replicaFor(&builder.Select{Table: "users"}))
+}
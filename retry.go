@@ -0,0 +1,135 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// DefaultRetryPolicy is the retry policy used when the fields of a RetryPolicy are empty (zero).
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     50 * time.Millisecond,
+	MaxBackoff:  time.Second,
+	Jitter:      0.5,
+}
+
+// RetryPolicy configure the retries of a transaction.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, doubled at each retry.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of the delay randomly removed (between 0 and 1),
+	// a negative value disables it.
+	Jitter float64
+
+	// Retryable says if the error can be retried (IsRetryable by default).
+	Retryable func(error) bool
+}
+
+// IsRetryable says if err is a deadlock, a lock wait timeout or a serialization failure.
+func IsRetryable(err error) bool {
+	err = classifyError(err)
+	return errors.Is(err, ErrDeadlock) ||
+		errors.Is(err, ErrLockWaitTimeout) ||
+		errors.Is(err, ErrSerialization)
+}
+
+// withDefaults returns the policy completed by DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultRetryPolicy.Backoff
+	}
+
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+
+	switch {
+	case p.Jitter == 0:
+		p.Jitter = DefaultRetryPolicy.Jitter
+	case p.Jitter < 0:
+		p.Jitter = 0
+	case p.Jitter > 1:
+		p.Jitter = 1
+	}
+
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+// delay returns the delay before the given attempt (starting at 2).
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 2; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d - time.Duration(rand.Float64()*p.Jitter*float64(d))
+}
+
+// RunTxRetry run a bunch of TxFunc like RunTx and re-run them in a new transaction
+// when the transaction fails with a retryable error.
+// On a transactional connection, the funcs are run once in a savepoint since
+// the error aborts the outer transaction.
+func (conn *db) RunTxRetry(policy RetryPolicy, level sql.IsolationLevel, funcs ...TxFunc) error {
+	return conn.RunTxRetryContext(context.Background(), policy, level, funcs...)
+}
+
+// RunTxRetryContext is the context version of RunTxRetry.
+func (conn *db) RunTxRetryContext(ctx context.Context, policy RetryPolicy, level sql.IsolationLevel, funcs ...TxFunc) error {
+	return conn.RunTxRetryWithContext(ctx, policy, conn.levelOptions(level), funcs...)
+}
+
+// RunTxRetryWith run a bunch of TxFunc like RunTxWith and retry them like RunTxRetry.
+func (conn *db) RunTxRetryWith(policy RetryPolicy, opts TxOptions, funcs ...TxFunc) error {
+	return conn.RunTxRetryWithContext(context.Background(), policy, opts, funcs...)
+}
+
+// RunTxRetryWithContext is the context version of RunTxRetryWith.
+func (conn *db) RunTxRetryWithContext(ctx context.Context, policy RetryPolicy, opts TxOptions, funcs ...TxFunc) (err error) {
+	policy = policy.withDefaults()
+	for attempt := 1; ; attempt++ {
+		if err = conn.runTx(ctx, opts, attempt, funcs); err == nil {
+			return
+		}
+
+		if conn.IsTx() || attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			return
+		}
+
+		if conn.hasVerbose() {
+			conn.logErr.Printf("transaction attempt %d/%d failed: %s", attempt, policy.MaxAttempts, err.Error())
+		}
+
+		t := time.NewTimer(policy.delay(attempt + 1))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
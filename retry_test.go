@@ -0,0 +1,113 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&mysql.MySQLError{Number: 1213}))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1205})))
+	assert.True(t, IsRetryable(&pgError{Code: "40001"}))
+	assert.False(t, IsRetryable(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, IsRetryable(errors.New("other")))
+}
+
+func TestUnexportedRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+		Jitter:     -1,
+	}.withDefaults()
+
+	assert.Equal(t, DefaultRetryPolicy.MaxAttempts, p.MaxAttempts)
+	assert.Equal(t, 10*time.Millisecond, p.delay(2))
+	assert.Equal(t, 20*time.Millisecond, p.delay(3))
+	assert.Equal(t, 40*time.Millisecond, p.delay(4))
+	assert.Equal(t, 50*time.Millisecond, p.delay(5))
+	assert.Equal(t, 50*time.Millisecond, p.delay(100))
+
+	assert.Equal(t, DefaultRetryPolicy.Jitter, RetryPolicy{}.withDefaults().Jitter)
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := p.delay(2)
+		assert.True(t, d >= 5*time.Millisecond && d <= 10*time.Millisecond)
+	}
+}
+
+func TestRunTxRetry(t *testing.T) {
+	if !TestMysql {
+		return
+	}
+
+	conn, err := Open()
+	assert.NoError(t, err)
+
+	attempts := 0
+	err = conn.RunTxRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, IsolationLevel, func(Connection) error {
+		if attempts++; attempts < 3 {
+			return &mysql.MySQLError{Number: 1213}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestUnexportedRunTxRetry(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+
+	// Retried until success.
+	{
+		var attempts []int
+		err := conn.RunTxRetry(policy, IsolationLevel, func(tx Connection) error {
+			attempts = append(attempts, tx.(*db).attempt)
+			if len(attempts) < 3 {
+				return &mysql.MySQLError{Number: 1213}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, attempts)
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK", "BEGIN", "COMMIT"}, fakeRecorded())
+	}
+
+	// Not retried on a non retryable error, retried with TxOptions.
+	{
+		conn := newFakeDB()
+		defer conn.Close()
+
+		errOther := errors.New("other")
+		assert.Equal(t, errOther, conn.RunTxRetry(policy, IsolationLevel, func(Connection) error {
+			return errOther
+		}))
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fakeRecorded())
+
+		attempts := 0
+		err := conn.RunTxRetryWith(policy, TxOptions{ConsistentSnapshot: true}, func(Connection) error {
+			attempts++
+			return &mysql.MySQLError{Number: 1205}
+		})
+		assert.True(t, IsRetryable(err))
+		assert.Equal(t, 3, attempts)
+	}
+}
+
+func TestQueryAttempt(t *testing.T) {
+	assert.Equal(t, 2, QueryAttempt(&qs{attempt: 2}))
+	assert.Equal(t, 0, QueryAttempt(nil))
+}
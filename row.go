@@ -0,0 +1,528 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+var (
+	// ErrNoColumn is returned by Row when the column does not exist.
+	ErrNoColumn = errors.New("database: no such column")
+
+	// ErrNullValue is returned by Row when the value is NULL and the getter is not nullable.
+	ErrNullValue = errors.New("database: null value")
+
+	// ErrConversion is returned by Row when the value cannot be converted.
+	ErrConversion = errors.New("database: conversion error")
+)
+
+// timeLayouts are the layouts used to parse textual dates.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02",
+}
+
+// Row is a result row with typed getters.
+// A Row is owned by the mapper, it can be retained after the mapper returns.
+type Row struct {
+	index  map[string]int
+	values []any
+}
+
+// newRow create a new Row.
+func newRow(index map[string]int, values []any) Row {
+	return Row{
+		index:  index,
+		values: values,
+	}
+}
+
+// Len returns the number of columns.
+func (r Row) Len() int {
+	return len(r.values)
+}
+
+// Has says if the column exists.
+func (r Row) Has(col string) bool {
+	_, ok := r.index[col]
+	return ok
+}
+
+// IsNull says if the value of the column is NULL.
+func (r Row) IsNull(col string) bool {
+	v, err := r.value(col)
+	return err == nil && v == nil
+}
+
+// Value returns the raw value of the column as returned by the driver.
+func (r Row) Value(col string) (any, error) {
+	return r.value(col)
+}
+
+// value returns the value of the column.
+func (r Row) value(col string) (any, error) {
+	i, ok := r.index[col]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoColumn, col)
+	}
+	return r.values[i], nil
+}
+
+// notNull returns the value of the column or an error if NULL.
+func (r Row) notNull(col string) (any, error) {
+	v, err := r.value(col)
+	if err == nil && v == nil {
+		err = fmt.Errorf("%w: %q", ErrNullValue, col)
+	}
+	return v, err
+}
+
+// Int64 returns the value of the column as int64.
+func (r Row) Int64(col string) (int64, error) {
+	v, err := r.notNull(col)
+	if err != nil {
+		return 0, err
+	}
+	return castInt64(col, v)
+}
+
+// Uint64 returns the value of the column as uint64.
+func (r Row) Uint64(col string) (uint64, error) {
+	v, err := r.notNull(col)
+	if err != nil {
+		return 0, err
+	}
+	return castUint64(col, v)
+}
+
+// Float64 returns the value of the column as float64.
+func (r Row) Float64(col string) (float64, error) {
+	v, err := r.notNull(col)
+	if err != nil {
+		return 0, err
+	}
+	return castFloat64(col, v)
+}
+
+// String returns the value of the column as string.
+func (r Row) String(col string) (string, error) {
+	v, err := r.notNull(col)
+	if err != nil {
+		return "", err
+	}
+	return castString(col, v)
+}
+
+// Bytes returns a copy of the value of the column as []byte.
+func (r Row) Bytes(col string) ([]byte, error) {
+	v, err := r.value(col)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte{}, v...), nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, conversionError(col, v, "[]byte")
+}
+
+// Bool returns the value of the column as bool.
+func (r Row) Bool(col string) (bool, error) {
+	v, err := r.notNull(col)
+	if err != nil {
+		return false, err
+	}
+	return castBool(col, v)
+}
+
+// Time returns the value of the column as time.Time.
+func (r Row) Time(col string) (time.Time, error) {
+	v, err := r.notNull(col)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return castTime(col, v)
+}
+
+// NullableInt64 returns the value of the column as *int64 (nil if NULL).
+func (r Row) NullableInt64(col string) (*int64, error) {
+	return nullable(r, col, castInt64)
+}
+
+// NullableUint64 returns the value of the column as *uint64 (nil if NULL).
+func (r Row) NullableUint64(col string) (*uint64, error) {
+	return nullable(r, col, castUint64)
+}
+
+// NullableFloat64 returns the value of the column as *float64 (nil if NULL).
+func (r Row) NullableFloat64(col string) (*float64, error) {
+	return nullable(r, col, castFloat64)
+}
+
+// NullableString returns the value of the column as *string (nil if NULL).
+func (r Row) NullableString(col string) (*string, error) {
+	return nullable(r, col, castString)
+}
+
+// NullableBool returns the value of the column as *bool (nil if NULL).
+func (r Row) NullableBool(col string) (*bool, error) {
+	return nullable(r, col, castBool)
+}
+
+// NullableTime returns the value of the column as *time.Time (nil if NULL).
+func (r Row) NullableTime(col string) (*time.Time, error) {
+	return nullable(r, col, castTime)
+}
+
+// Decode the row into the struct pointed by into, mapped like SelectAll.
+// The columns without field are ignored.
+func (r Row) Decode(into any) error {
+	v := reflect.ValueOf(into)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: Decode expects a pointer to a struct, got %T", ErrConversion, into)
+	}
+
+	v = v.Elem()
+	fields := fieldsOf(v.Type())
+	for col, i := range r.index {
+		index := fieldOf(fields, col)
+		if index == nil {
+			continue
+		}
+
+		if err := assign(col, v.FieldByIndex(index), r.values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullable convert the value of the column with fn unless it is NULL.
+func nullable[T any](r Row, col string, fn func(string, any) (T, error)) (*T, error) {
+	v, err := r.value(col)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	out, err := fn(col, v)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// assign the value v to the field f.
+func assign(col string, f reflect.Value, v any) (err error) {
+	if s, ok := f.Addr().Interface().(sql.Scanner); ok {
+		if err = s.Scan(v); err != nil {
+			err = fmt.Errorf("%w: column %q: %s", ErrConversion, col, err.Error())
+		}
+		return
+	}
+
+	if f.Kind() == reflect.Pointer {
+		if v == nil {
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		}
+
+		p := reflect.New(f.Type().Elem())
+		if err = assign(col, p.Elem(), v); err == nil {
+			f.Set(p)
+		}
+		return
+	}
+
+	if v == nil {
+		return fmt.Errorf("%w: %q", ErrNullValue, col)
+	}
+
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = castInt64(col, v); err == nil {
+			if f.OverflowInt(n) {
+				return conversionError(col, v, f.Type().String())
+			}
+			f.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = castUint64(col, v); err == nil {
+			if f.OverflowUint(n) {
+				return conversionError(col, v, f.Type().String())
+			}
+			f.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = castFloat64(col, v); err == nil {
+			f.SetFloat(n)
+		}
+	case reflect.String:
+		var s string
+		if s, err = castString(col, v); err == nil {
+			f.SetString(s)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = castBool(col, v); err == nil {
+			f.SetBool(b)
+		}
+	default:
+		switch f.Interface().(type) {
+		case time.Time:
+			var t time.Time
+			if t, err = castTime(col, v); err == nil {
+				f.Set(reflect.ValueOf(t))
+			}
+		case []byte:
+			b, ok := v.([]byte)
+			if !ok {
+				return conversionError(col, v, "[]byte")
+			}
+			f.SetBytes(append([]byte{}, b...))
+		default:
+			if rv := reflect.ValueOf(v); rv.Type().AssignableTo(f.Type()) {
+				f.Set(rv)
+			} else {
+				err = conversionError(col, v, f.Type().String())
+			}
+		}
+	}
+	return
+}
+
+// conversionError returns an error for a value that cannot be converted to t.
+func conversionError(col string, v any, t string) error {
+	return fmt.Errorf("%w: column %q: cannot convert %T to %s", ErrConversion, col, v, t)
+}
+
+// castInt64 convert v to int64.
+func castInt64(col string, v any) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, conversionError(col, v, "int64")
+		}
+		return int64(v), nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), nil
+		}
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case []byte:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return n, nil
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, conversionError(col, v, "int64")
+}
+
+// castUint64 convert v to uint64.
+func castUint64(col string, v any) (uint64, error) {
+	switch v := v.(type) {
+	case uint64:
+		return v, nil
+	case []byte:
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return n, nil
+		}
+	case string:
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return n, nil
+		}
+	default:
+		if n, err := castInt64(col, v); err == nil && n >= 0 {
+			return uint64(n), nil
+		}
+	}
+	return 0, conversionError(col, v, "uint64")
+}
+
+// castFloat64 convert v to float64.
+func castFloat64(col string, v any) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case []byte:
+		if n, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return n, nil
+		}
+	case string:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, conversionError(col, v, "float64")
+}
+
+// castString convert v to string.
+func castString(col string, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return "", conversionError(col, v, "string")
+}
+
+// castBool convert v to bool.
+func castBool(col string, v any) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case []byte:
+		if b, err := strconv.ParseBool(string(v)); err == nil {
+			return b, nil
+		}
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, conversionError(col, v, "bool")
+}
+
+// castTime convert v to time.Time.
+func castTime(col string, v any) (time.Time, error) {
+	var s string
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return time.Time{}, conversionError(col, v, "time.Time")
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, conversionError(col, v, "time.Time")
+}
+
+// -------------------------------------------------
+
+// SelectRows run an SELECT query to fetch multiple results using a row mapper.
+func (conn *db) SelectRows(stmt Stmt, mapper RowMapper) (int, error) {
+	return conn.runRows(context.Background(), stmt, 0, mapper)
+}
+
+// SelectRowsContext is the context version of SelectRows.
+func (conn *db) SelectRowsContext(ctx context.Context, stmt Stmt, mapper RowMapper) (int, error) {
+	return conn.runRows(ctx, stmt, 0, mapper)
+}
+
+// SelectRow run an SELECT query to fetch a single result using a row mapper.
+func (conn *db) SelectRow(stmt Stmt, mapper RowMapper) (int, error) {
+	return conn.runRows(context.Background(), stmt, 1, mapper)
+}
+
+// SelectRowContext is the context version of SelectRow.
+func (conn *db) SelectRowContext(ctx context.Context, stmt Stmt, mapper RowMapper) (int, error) {
+	return conn.runRows(ctx, stmt, 1, mapper)
+}
+
+// QueryRows run an SELECT query to fetch multiple results using a row mapper.
+func (conn *db) QueryRows(query string, mapper RowMapper, args ...any) (int, error) {
+	return conn.runRows(context.Background(), builder.NewQuery(query, args...), 0, mapper)
+}
+
+// QueryRowsContext is the context version of QueryRows.
+func (conn *db) QueryRowsContext(ctx context.Context, query string, mapper RowMapper, args ...any) (int, error) {
+	return conn.runRows(ctx, builder.NewQuery(query, args...), 0, mapper)
+}
+
+// QueryRow run an SELECT query to fetch a single result using a row mapper.
+func (conn *db) QueryRow(query string, mapper RowMapper, args ...any) (int, error) {
+	return conn.runRows(context.Background(), builder.NewQuery(query, args...), 1, mapper)
+}
+
+// QueryRowContext is the context version of QueryRow.
+func (conn *db) QueryRowContext(ctx context.Context, query string, mapper RowMapper, args ...any) (int, error) {
+	return conn.runRows(ctx, builder.NewQuery(query, args...), 1, mapper)
+}
+
+// runRows run stmt with results mapped with a RowMapper.
+func (conn *db) runRows(ctx context.Context, stmt Stmt, limit int, mapper RowMapper) (int, error) {
+	var index map[string]int
+	return conn.runScan(ctx, stmt, limit, func(rows *sqlx.Rows) error {
+		if index == nil {
+			cols, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+
+			index = make(map[string]int, len(cols))
+			for i, col := range cols {
+				index[col] = i
+			}
+		}
+
+		values, err := rows.SliceScan()
+		if err != nil {
+			return err
+		}
+
+		mapper(newRow(index, values))
+		return nil
+	})
+}
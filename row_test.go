@@ -0,0 +1,154 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+func TestUnexportedRow(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := newRow(map[string]int{
+		"id":         0,
+		"title":      1,
+		"price":      2,
+		"enabled":    3,
+		"created_at": 4,
+		"deleted_at": 5,
+	}, []any{[]byte("12"), []byte("hello"), 9.5, int64(1), now, nil})
+
+	id, err := r.Int64("id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), id)
+
+	title, err := r.String("title")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", title)
+
+	price, err := r.Float64("price")
+	assert.NoError(t, err)
+	assert.Equal(t, 9.5, price)
+
+	enabled, err := r.Bool("enabled")
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	createdAt, err := r.Time("created_at")
+	assert.NoError(t, err)
+	assert.Equal(t, now, createdAt)
+
+	deletedAt, err := r.NullableTime("deleted_at")
+	assert.NoError(t, err)
+	assert.Nil(t, deletedAt)
+	assert.True(t, r.IsNull("deleted_at"))
+
+	_, err = r.Time("deleted_at")
+	assert.ErrorIs(t, err, ErrNullValue)
+
+	_, err = r.Int64("title")
+	assert.ErrorIs(t, err, ErrConversion)
+
+	_, err = r.String("unknown")
+	assert.ErrorIs(t, err, ErrNoColumn)
+}
+
+func TestUnexportedRowDecode(t *testing.T) {
+	type article struct {
+		ID        uint64
+		Title     string
+		DeletedAt *time.Time
+	}
+
+	r := newRow(map[string]int{
+		"id":         0,
+		"title":      1,
+		"deleted_at": 2,
+		"extra":      3,
+	}, []any{int64(7), []byte("hello"), []byte("2019-01-02 03:04:05"), "ignored"})
+
+	var a article
+	assert.NoError(t, r.Decode(&a))
+	assert.Equal(t, uint64(7), a.ID)
+	assert.Equal(t, "hello", a.Title)
+	assert.Equal(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC), *a.DeletedAt)
+
+	assert.ErrorIs(t, r.Decode(a), ErrConversion)
+
+	// The columns are matched case-insensitively, like SelectAll.
+	{
+		type author struct {
+			UserID   uint64
+			Nickname string `db:"nickname"`
+		}
+
+		r := newRow(map[string]int{"UserID": 0, "NickName": 1}, []any{int64(3), []byte("kovacou")})
+
+		var a author
+		assert.NoError(t, r.Decode(&a))
+		assert.Equal(t, author{UserID: 3, Nickname: "kovacou"}, a)
+	}
+}
+
+func TestSelectRows(t *testing.T) {
+	if !TestMysql {
+		return
+	}
+
+	conn, err := OpenEnv("DSN")
+	assert.NoError(t, err)
+
+	var rows []Row
+	n, err := conn.SelectRows(&builder.Select{Table: "articles"}, func(r Row) {
+		rows = append(rows, r)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, n, len(rows))
+
+	for _, r := range rows {
+		_, err := r.Int64("id")
+		assert.NoError(t, err)
+	}
+}
+
+func TestUnexportedSelectRows(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	query := "SELECT id, title FROM articles"
+	fakeRows(query, []string{"id", "title"}, []driver.Value{int64(1), []byte("first")}, []driver.Value{int64(2), []byte("second")})
+
+	var rows []Row
+	n, err := conn.SelectRows(builder.NewQuery(query), func(r Row) {
+		rows = append(rows, r)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	if assert.Len(t, rows, 2) {
+		// the rows are retained by the mapper.
+		title, err := rows[0].String("title")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", title)
+
+		id, err := rows[1].Int64("id")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), id)
+	}
+
+	n, err = conn.QueryRow(query, func(r Row) {
+		title, err := r.String("title")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", title)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,209 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrScanUnsupported is returned when the connection does not support the struct scanning.
+var ErrScanUnsupported = errors.New("database: connection does not support struct scanning")
+
+var (
+	// plans is the cache of the fields by struct type.
+	plans sync.Map
+
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// scanner is implemented by the connections supporting struct scanning.
+type scanner interface {
+	runScan(ctx context.Context, stmt Stmt, limit int, scan func(*sqlx.Rows) error) (int, error)
+}
+
+// SelectAll run stmt and scan the results into a slice of T.
+// T is a struct mapped with the "db" tag (snake_case of the field name by default)
+// or a scalar when a single column is selected.
+func SelectAll[T any](conn Connection, stmt Stmt) ([]T, error) {
+	return SelectAllContext[T](context.Background(), conn, stmt)
+}
+
+// SelectAllContext is the context version of SelectAll.
+func SelectAllContext[T any](ctx context.Context, conn Connection, stmt Stmt) ([]T, error) {
+	s, ok := conn.(scanner)
+	if !ok {
+		return nil, ErrScanUnsupported
+	}
+
+	var (
+		out  []T
+		dest []any
+		p    *plan
+	)
+
+	_, err := s.runScan(ctx, stmt, 0, func(rows *sqlx.Rows) (err error) {
+		var v T
+		if p == nil {
+			if p, err = planOf(reflect.TypeOf(v), rows); err != nil {
+				return
+			}
+			dest = make([]any, len(p.fields))
+		}
+
+		if err = rows.Scan(p.dest(reflect.ValueOf(&v).Elem(), dest)...); err == nil {
+			out = append(out, v)
+		}
+		return
+	})
+	return out, err
+}
+
+// SelectOne run stmt and scan the first result into T.
+// When there is no result, Environment.ErrorNoRows says if sql.ErrNoRows is returned.
+func SelectOne[T any](conn Connection, stmt Stmt) (T, error) {
+	return SelectOneContext[T](context.Background(), conn, stmt)
+}
+
+// SelectOneContext is the context version of SelectOne.
+func SelectOneContext[T any](ctx context.Context, conn Connection, stmt Stmt) (out T, err error) {
+	s, ok := conn.(scanner)
+	if !ok {
+		return out, ErrScanUnsupported
+	}
+
+	_, err = s.runScan(ctx, stmt, 1, func(rows *sqlx.Rows) error {
+		p, err := planOf(reflect.TypeOf(out), rows)
+		if err != nil {
+			return err
+		}
+		return rows.Scan(p.dest(reflect.ValueOf(&out).Elem(), make([]any, len(p.fields)))...)
+	})
+	return
+}
+
+// plan is the list of fields to scan for each column of a result.
+// A nil field means the column is discarded.
+type plan struct {
+	scalar bool
+	fields [][]int
+}
+
+// dest fill dest with the pointers to the fields of v.
+func (p *plan) dest(v reflect.Value, dest []any) []any {
+	if p.scalar {
+		dest[0] = v.Addr().Interface()
+		return dest
+	}
+
+	for i, index := range p.fields {
+		if index == nil {
+			dest[i] = new(any)
+		} else {
+			dest[i] = v.FieldByIndex(index).Addr().Interface()
+		}
+	}
+	return dest
+}
+
+// planOf compute the plan of the type t for the columns of rows.
+func planOf(t reflect.Type, rows *sqlx.Rows) (*plan, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Kind() != reflect.Struct || t.Implements(scannerType) || reflect.PtrTo(t).Implements(scannerType) || t == timeType {
+		if len(cols) != 1 {
+			return nil, errors.New("database: scanning a scalar requires a single column")
+		}
+		return &plan{scalar: true, fields: [][]int{nil}}, nil
+	}
+
+	fields := fieldsOf(t)
+	p := &plan{fields: make([][]int, len(cols))}
+	for i, col := range cols {
+		p.fields[i] = fieldOf(fields, col)
+	}
+	return p, nil
+}
+
+// fieldOf returns the index of the field of the column col, matched by its lowercase
+// or snake_case name (UserID → userid, user_id), nil if there is none.
+func fieldOf(fields map[string][]int, col string) []int {
+	if index, ok := fields[strings.ToLower(col)]; ok {
+		return index
+	}
+	return fields[toSnake(col)]
+}
+
+// fieldsOf returns the index of the fields of the struct t by column name.
+func fieldsOf(t reflect.Type) map[string][]int {
+	if v, ok := plans.Load(t); ok {
+		return v.(map[string][]int)
+	}
+
+	out := map[string][]int{}
+	walkFields(t, nil, out)
+	plans.Store(t, out)
+	return out
+}
+
+// walkFields walk the fields of t, embedded structs included.
+func walkFields(t reflect.Type, parent []int, out map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("db")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+
+		index := append(append([]int{}, parent...), i)
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			walkFields(f.Type, index, out)
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := strings.ToLower(strings.Split(tag, ",")[0])
+		if name == "" {
+			name = toSnake(f.Name)
+		}
+
+		if _, ok := out[name]; !ok {
+			out[name] = index
+		}
+	}
+}
+
+// toSnake convert a field name to snake_case (AuthorID → author_id).
+func toSnake(name string) string {
+	runes := []rune(name)
+	out := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				out.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
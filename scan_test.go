@@ -0,0 +1,132 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+func TestUnexportedToSnake(t *testing.T) {
+	for in, out := range map[string]string{
+		"ID":        "id",
+		"AuthorID":  "author_id",
+		"CreatedAt": "created_at",
+		"HTTPCode":  "http_code",
+		"Address2":  "address2",
+	} {
+		assert.Equal(t, out, toSnake(in))
+	}
+}
+
+func TestUnexportedFieldsOf(t *testing.T) {
+	type base struct {
+		ID uint64
+	}
+
+	type row struct {
+		base
+		Name    string `db:"nickname"`
+		Ignored string `db:"-"`
+		private string
+	}
+
+	fields := fieldsOf(reflect.TypeOf(row{}))
+	assert.Equal(t, map[string][]int{
+		"id":       {0, 0},
+		"nickname": {1},
+	}, fields)
+}
+
+func TestSelectAll(t *testing.T) {
+	if !TestMysql {
+		return
+	}
+
+	conn, err := OpenEnv("DSN")
+	assert.NoError(t, err)
+
+	articles, err := SelectAll[Article](conn, &builder.Select{
+		Table:   "articles",
+		OrderBy: builder.ParseOrderBy("id"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, articles, 2)
+	assert.Equal(t, uint64(2), articles[1].AuthorID)
+
+	ids, err := SelectAll[int64](conn, builder.NewQuery("SELECT id FROM authors ORDER BY id"))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestSelectOne(t *testing.T) {
+	if !TestMysql {
+		return
+	}
+
+	conn, err := OpenEnv("DSN")
+	assert.NoError(t, err)
+
+	author, err := SelectOne[Author](conn, builder.NewQuery("SELECT * FROM authors WHERE id = ?", 2))
+	assert.NoError(t, err)
+	assert.Equal(t, "Saucisse", author.Nickname)
+	assert.Nil(t, author.Lastname)
+}
+
+func TestUnexportedSelectAll(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	query := "SELECT * FROM authors"
+	fakeRows(query, []string{"ID", "nickname", "lastname"},
+		[]driver.Value{int64(1), []byte("Kovacou"), []byte("Kovac")},
+		[]driver.Value{int64(2), []byte("Saucisse"), nil},
+	)
+
+	authors, err := SelectAll[Author](conn, builder.NewQuery(query))
+	assert.NoError(t, err)
+	if assert.Len(t, authors, 2) {
+		assert.Equal(t, uint64(1), authors[0].ID)
+		assert.Equal(t, "Kovac", *authors[0].Lastname)
+		assert.Equal(t, "Saucisse", authors[1].Nickname)
+		assert.Nil(t, authors[1].Lastname)
+	}
+
+	fakeRows("SELECT id FROM authors", []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)})
+	ids, err := SelectAll[int64](conn, builder.NewQuery("SELECT id FROM authors"))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids)
+
+	_, err = SelectAll[int64](conn, builder.NewQuery(query))
+	assert.Error(t, err)
+}
+
+func TestUnexportedSelectOne(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	query := "SELECT * FROM authors WHERE id = ?"
+	fakeRows(query, []string{"id", "nickname"}, []driver.Value{int64(2), []byte("Saucisse")}, []driver.Value{int64(3), []byte("Other")})
+
+	author, err := SelectOne[Author](conn, builder.NewQuery(query, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, "Saucisse", author.Nickname)
+
+	fakeRows(query, []string{"id", "nickname"})
+	author, err = SelectOne[Author](conn, builder.NewQuery(query, 4))
+	assert.NoError(t, err)
+	assert.Zero(t, author)
+
+	conn.env.ErrorNoRows = true
+	_, err = SelectOne[Author](conn, builder.NewQuery(query, 4))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
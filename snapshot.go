@@ -0,0 +1,116 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Consistent snapshot keywords.
+const (
+	setRepeatableReadKeyword = "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ"
+	commitKeyword            = "COMMIT"
+	rollbackKeyword          = "ROLLBACK"
+)
+
+// txConn is the transaction of a connection.
+// It is a *sqlx.Tx, or a *snapshotTx for the consistent snapshots of MySQL.
+type txConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error)
+	Commit() error
+	Rollback() error
+}
+
+// snapshotTx is a MySQL transaction started WITH CONSISTENT SNAPSHOT.
+// database/sql can't start it, it is run by hand on a pinned connection.
+type snapshotTx struct {
+	conn *sqlx.Conn
+	done chan struct{}
+	once sync.Once
+}
+
+// beginSnapshot pin a connection of dbx and start a consistent snapshot on it.
+// The transaction is rolled back if ctx is done before Commit or Rollback.
+func beginSnapshot(ctx context.Context, dbx *sqlx.DB, readOnly bool) (*snapshotTx, error) {
+	c, err := dbx.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := consistentSnapshotKeyword
+	if readOnly {
+		query += readOnlyKeyword
+	}
+
+	for _, q := range []string{setRepeatableReadKeyword, query} {
+		if _, err = c.ExecContext(ctx, q); err != nil {
+			discardConn(c)
+			return nil, err
+		}
+	}
+
+	tx := &snapshotTx{conn: c, done: make(chan struct{})}
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				_ = tx.Rollback()
+			case <-tx.done:
+			}
+		}()
+	}
+	return tx, nil
+}
+
+// ExecContext executes a query in the transaction.
+func (tx *snapshotTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.conn.ExecContext(ctx, query, args...)
+}
+
+// PreparexContext prepare a statement in the transaction.
+func (tx *snapshotTx) PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	return tx.conn.PreparexContext(ctx, query)
+}
+
+// Commit the transaction and release the connection.
+func (tx *snapshotTx) Commit() error {
+	return tx.end(commitKeyword)
+}
+
+// Rollback the transaction and release the connection.
+func (tx *snapshotTx) Rollback() error {
+	return tx.end(rollbackKeyword)
+}
+
+// end run the keyword and release the connection, only once.
+// sql.ErrTxDone is returned when the transaction is already ended.
+func (tx *snapshotTx) end(keyword string) (err error) {
+	err = sql.ErrTxDone
+	tx.once.Do(func() {
+		close(tx.done)
+		if _, err = tx.conn.ExecContext(context.Background(), keyword); err != nil {
+			discardConn(tx.conn)
+			return
+		}
+		err = tx.conn.Close()
+	})
+	return
+}
+
+// discardConn close c without returning it to the pool,
+// its transaction state is unknown.
+func discardConn(c *sqlx.Conn) {
+	_ = c.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	_ = c.Close()
+}
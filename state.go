@@ -20,10 +20,20 @@ type QueryState interface {
 	Start() time.Time
 	End() time.Time
 	Runtime() time.Duration
+	Err() error
 	String() string
 	Bytes() []byte
 }
 
+// QueryAttempt returns the attempt of the transaction the query ran in
+// (RunTxRetry), 0 when unknown.
+func QueryAttempt(s QueryState) int {
+	if a, ok := s.(interface{ Attempt() int }); ok {
+		return a.Attempt()
+	}
+	return 0
+}
+
 type qs struct {
 	query   string
 	args    []any
@@ -31,6 +41,8 @@ type qs struct {
 	ctxFlag []string
 	start   time.Time
 	end     time.Time
+	err     error
+	attempt int
 }
 
 // Runtime
@@ -48,6 +60,16 @@ func (p *qs) End() time.Time {
 	return p.end
 }
 
+// Err
+func (p *qs) Err() error {
+	return p.err
+}
+
+// Attempt
+func (p *qs) Attempt() int {
+	return p.attempt
+}
+
 // ContextID
 func (p *qs) ContextID() string {
 	return p.ctxID
@@ -4,3 +4,16 @@
 // license that can be found in the LICENSE file.
 
 package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnexportedQueryStateErr(t *testing.T) {
+	err := errors.New("mapper")
+	var s QueryState = &qs{err: err}
+	assert.Equal(t, err, s.Err())
+}
@@ -6,6 +6,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -16,6 +17,14 @@ import (
 	"github.com/kovacou/go-database/builder"
 )
 
+var (
+	// ErrLockOutsideTx is returned when a statement locking rows is run outside a transaction.
+	ErrLockOutsideTx = errors.New("database: locking clause used outside of a transaction")
+
+	// ErrStop can be returned by a mapper to stop the iteration without error.
+	ErrStop = errors.New("database: stop iteration")
+)
+
 // Stmt is the representation of an statement or query (SELECT, UPDATE, & DELETE)
 type Stmt interface {
 	String() string
@@ -24,97 +33,275 @@ type Stmt interface {
 
 // SelectMap run an SELECT query to fetch multiple results using a map mapper.
 func (conn *db) SelectMap(stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
-	return conn.runMap(stmt, mapper)
+	return conn.runMap(context.Background(), stmt, mapper)
+}
+
+// SelectMapContext is the context version of SelectMap.
+func (conn *db) SelectMapContext(ctx context.Context, stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
+	return conn.runMap(ctx, stmt, mapper)
 }
 
 // SelectMapRow run an SELECT query to fetch a single result using a map mapper.
 func (conn *db) SelectMapRow(stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
-	return conn.runMapRow(stmt, mapper)
+	return conn.runMapRow(context.Background(), stmt, mapper)
+}
+
+// SelectMapRowContext is the context version of SelectMapRow.
+func (conn *db) SelectMapRowContext(ctx context.Context, stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
+	return conn.runMapRow(ctx, stmt, mapper)
 }
 
 // SelectSlice run an SELECT query to fetch multiple results using a slice mapper.
 func (conn *db) SelectSlice(stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
-	return conn.runSlice(stmt, mapper)
+	return conn.runSlice(context.Background(), stmt, mapper)
+}
+
+// SelectSliceContext is the context version of SelectSlice.
+func (conn *db) SelectSliceContext(ctx context.Context, stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
+	return conn.runSlice(ctx, stmt, mapper)
 }
 
 // SelectSliceRow run an SELECT query to fetch a single result using a slice mapper.
 func (conn *db) SelectSliceRow(stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
-	return conn.runSliceRow(stmt, mapper)
+	return conn.runSliceRow(context.Background(), stmt, mapper)
+}
+
+// SelectSliceRowContext is the context version of SelectSliceRow.
+func (conn *db) SelectSliceRowContext(ctx context.Context, stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
+	return conn.runSliceRow(ctx, stmt, mapper)
+}
+
+// SelectMapErr run an SELECT query to fetch multiple results using a map mapper returning an error.
+func (conn *db) SelectMapErr(stmt Stmt, mapper MapMapperErr) (rowsReturned int, err error) {
+	return conn.runMapErr(context.Background(), stmt, mapper)
+}
+
+// SelectMapErrContext is the context version of SelectMapErr.
+func (conn *db) SelectMapErrContext(ctx context.Context, stmt Stmt, mapper MapMapperErr) (rowsReturned int, err error) {
+	return conn.runMapErr(ctx, stmt, mapper)
+}
+
+// SelectSliceErr run an SELECT query to fetch multiple results using a slice mapper returning an error.
+func (conn *db) SelectSliceErr(stmt Stmt, mapper SliceMapperErr) (rowsReturned int, err error) {
+	return conn.runSliceErr(context.Background(), stmt, mapper)
+}
+
+// SelectSliceErrContext is the context version of SelectSliceErr.
+func (conn *db) SelectSliceErrContext(ctx context.Context, stmt Stmt, mapper SliceMapperErr) (rowsReturned int, err error) {
+	return conn.runSliceErr(ctx, stmt, mapper)
 }
 
 // Exec run a statement.
+// Multi-row inserts are split in chunks according to the placeholders limit
+// of the driver and Environment.MaxPacket, run in a single transaction.
 func (conn *db) Exec(stmt Stmt) (res sql.Result, err error) {
-	if err := conn.Connect(); err != nil {
+	return conn.ExecContext(context.Background(), stmt)
+}
+
+// ExecContext is the context version of Exec.
+func (conn *db) ExecContext(ctx context.Context, stmt Stmt) (res sql.Result, err error) {
+	dbx, err := conn.pool()
+	if err != nil {
 		return nil, err
 	}
 
+	if i, ok := stmt.(*builder.Insert); ok && i.Len() > 1 {
+		if chunks := i.Chunks(conn.dialect().MaxPlaceholders(), conn.env.MaxPacket); len(chunks) > 1 {
+			return conn.execChunks(ctx, chunks)
+		}
+	}
+
+	ctx, cancel := conn.withTimeout(ctx)
+	defer cancel()
+
 	var t time.Time
 	if conn.hasProfiling() {
 		t = time.Now()
 	}
 
+	query, err := conn.query(stmt)
+	if err != nil {
+		return nil, err
+	}
+
 	if conn.tx != nil {
-		res, err = conn.tx.Exec(stmt.String(), stmt.Args()...)
+		res, err = conn.tx.ExecContext(ctx, query, stmt.Args()...)
 	} else {
-		res, err = (*conn.dbx).Exec(stmt.String(), stmt.Args()...)
+		res, err = dbx.ExecContext(ctx, query, stmt.Args()...)
 	}
 
+	err = conn.classify(err)
 	conn.profilingStmt(stmt, err, t)
 	return
 }
 
+// execChunks run the chunks of a multi-row insert sequentially.
+// Outside a transaction, the chunks are run in a new one to keep the insert atomic.
+func (conn *db) execChunks(ctx context.Context, chunks []*builder.Insert) (res sql.Result, err error) {
+	if conn.tx == nil {
+		err = conn.RunTxContext(ctx, IsolationLevel, func(tx Connection) (err error) {
+			res, err = tx.(*db).execChunks(ctx, chunks)
+			return
+		})
+
+		if err != nil {
+			return nil, err
+		}
+		return
+	}
+
+	out := &batchResult{}
+	for n, chunk := range chunks {
+		res, err := conn.ExecContext(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		if n == 0 {
+			out.lastInsertID, out.lastInsertIDErr = res.LastInsertId()
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			out.rowsAffectedErr = err
+		}
+		out.rowsAffected += affected
+	}
+	return out, nil
+}
+
+// batchResult is the sql.Result of multiple statements.
+type batchResult struct {
+	lastInsertID    int64
+	lastInsertIDErr error
+	rowsAffected    int64
+	rowsAffectedErr error
+}
+
+// LastInsertId returns the id of the first statement.
+func (r *batchResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, r.lastInsertIDErr
+}
+
+// RowsAffected returns the sum of the rows affected by the statements.
+func (r *batchResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, r.rowsAffectedErr
+}
+
 // QuerySlice run an SELECT query to fetch a multiple results using a slice mapper.
 func (conn *db) QuerySlice(query string, mapper SliceMapper, args ...any) (rowsReturned int, err error) {
-	return conn.runSlice(builder.NewQuery(query, args...), mapper)
+	return conn.runSlice(context.Background(), builder.NewQuery(query, args...), mapper)
+}
+
+// QuerySliceContext is the context version of QuerySlice.
+func (conn *db) QuerySliceContext(ctx context.Context, query string, mapper SliceMapper, args ...any) (rowsReturned int, err error) {
+	return conn.runSlice(ctx, builder.NewQuery(query, args...), mapper)
 }
 
 // QuerySliceRow run an SELECT query to fetch a single result using a slice mapper.
 func (conn *db) QuerySliceRow(query string, mapper SliceMapper, args ...any) (rowsReturned int, err error) {
-	return conn.runSliceRow(builder.NewQuery(query, args...), mapper)
+	return conn.runSliceRow(context.Background(), builder.NewQuery(query, args...), mapper)
+}
+
+// QuerySliceRowContext is the context version of QuerySliceRow.
+func (conn *db) QuerySliceRowContext(ctx context.Context, query string, mapper SliceMapper, args ...any) (rowsReturned int, err error) {
+	return conn.runSliceRow(ctx, builder.NewQuery(query, args...), mapper)
+}
+
+// QuerySliceErr run an SELECT query to fetch multiple results using a slice mapper returning an error.
+func (conn *db) QuerySliceErr(query string, mapper SliceMapperErr, args ...any) (rowsReturned int, err error) {
+	return conn.runSliceErr(context.Background(), builder.NewQuery(query, args...), mapper)
+}
+
+// QuerySliceErrContext is the context version of QuerySliceErr.
+func (conn *db) QuerySliceErrContext(ctx context.Context, query string, mapper SliceMapperErr, args ...any) (rowsReturned int, err error) {
+	return conn.runSliceErr(ctx, builder.NewQuery(query, args...), mapper)
+}
+
+// QueryMapErr run an SELECT query to fetch multiple results using a map mapper returning an error.
+func (conn *db) QueryMapErr(query string, mapper MapMapperErr, args ...any) (rowsReturned int, err error) {
+	return conn.runMapErr(context.Background(), builder.NewQuery(query, args...), mapper)
+}
+
+// QueryMapErrContext is the context version of QueryMapErr.
+func (conn *db) QueryMapErrContext(ctx context.Context, query string, mapper MapMapperErr, args ...any) (rowsReturned int, err error) {
+	return conn.runMapErr(ctx, builder.NewQuery(query, args...), mapper)
 }
 
 // QueryMap run an SELECT query to fetch multiple results using a map mapper.
 func (conn *db) QueryMap(query string, mapper MapMapper, args ...any) (rowsReturned int, err error) {
-	return conn.runMap(builder.NewQuery(query, args...), mapper)
+	return conn.runMap(context.Background(), builder.NewQuery(query, args...), mapper)
+}
+
+// QueryMapContext is the context version of QueryMap.
+func (conn *db) QueryMapContext(ctx context.Context, query string, mapper MapMapper, args ...any) (rowsReturned int, err error) {
+	return conn.runMap(ctx, builder.NewQuery(query, args...), mapper)
 }
 
 // QueryMapRow run an SELECT query to fetch a single result using a map mapper.
 func (conn *db) QueryMapRow(query string, mapper MapMapper, args ...any) (rowsReturned int, err error) {
-	return conn.runMapRow(builder.NewQuery(query, args...), mapper)
+	return conn.runMapRow(context.Background(), builder.NewQuery(query, args...), mapper)
+}
+
+// QueryMapRowContext is the context version of QueryMapRow.
+func (conn *db) QueryMapRowContext(ctx context.Context, query string, mapper MapMapper, args ...any) (rowsReturned int, err error) {
+	return conn.runMapRow(ctx, builder.NewQuery(query, args...), mapper)
 }
 
 // runMap run stmt with a multiple results expected and mapped with a MapMapper.
-func (conn *db) runMap(stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
+func (conn *db) runMap(ctx context.Context, stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
+	return conn.runMapErr(ctx, stmt, func(row map[string]any) error {
+		mapper(row)
+		return nil
+	})
+}
+
+// runMapErr run stmt with a multiple results expected and mapped with a MapMapperErr.
+func (conn *db) runMapErr(ctx context.Context, stmt Stmt, mapper MapMapperErr) (rowsReturned int, err error) {
+	row := map[string]any{}
+	return conn.runScan(ctx, stmt, 0, func(rows *sqlx.Rows) error {
+		if err := rows.MapScan(row); err != nil {
+			return err
+		}
+		return mapper(row)
+	})
+}
+
+// runMapRow run stmt with a single result expected and mapped with a MapMapper.
+func (conn *db) runMapRow(ctx context.Context, stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
+	if node := conn.replicaFor(stmt); node != nil {
+		return conn.readFrom(node, func(c *db) (int, error) {
+			return c.runMapRow(ctx, stmt, mapper)
+		})
+	}
+
 	if err = conn.Connect(); err != nil {
 		return
 	}
 
+	ctx, cancel := conn.withTimeout(ctx)
+	defer cancel()
+
 	var (
-		stmtx *sqlx.Stmt
-		rows  *sqlx.Rows
-		t     time.Time
+		release func() error
+		stmtx   *sqlx.Stmt
+		t       time.Time
+		values  = map[string]any{}
 	)
 
 	if conn.hasProfiling() {
 		t = time.Now()
 	}
 
-	stmtx, err = preparex(conn, stmt)
+	stmtx, release, err = preparex(ctx, conn, stmt)
 	if err == nil {
-		defer stmtx.Close()
-		rows, err = stmtx.Queryx(stmt.Args()...)
-		if err == nil {
-			defer rows.Close()
+		defer release()
 
-			row := map[string]any{}
-			for rows.Next() {
-				err = rows.MapScan(row)
-				if err != nil {
-					break
-				}
-				mapper(row)
-				rowsReturned++
-			}
+		err = stmtx.QueryRowxContext(ctx, stmt.Args()...).MapScan(values)
+		conn.markFirstRow()
+		if err == nil {
+			mapper(values)
+			rowsReturned = 1
 		} else if errors.Is(err, sql.ErrNoRows) {
 			if !conn.env.ErrorNoRows {
 				err = nil
@@ -122,6 +309,7 @@ func (conn *db) runMap(stmt Stmt, mapper MapMapper) (rowsReturned int, err error
 		}
 	}
 
+	err = conn.classify(err)
 	if err != nil && conn.hasVerbose() {
 		conn.logErr.Println(err.Error())
 	}
@@ -130,27 +318,56 @@ func (conn *db) runMap(stmt Stmt, mapper MapMapper) (rowsReturned int, err error
 	return
 }
 
-// runMapRow run stmt with a single result expected and mapped with a MapMapper.
-func (conn *db) runMapRow(stmt Stmt, mapper MapMapper) (rowsReturned int, err error) {
+// runSlice run stmt with a multiple results and mapped with a SliceMapper.
+func (conn *db) runSlice(ctx context.Context, stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
+	return conn.runSliceErr(ctx, stmt, func(values []any) error {
+		mapper(values)
+		return nil
+	})
+}
+
+// runSliceErr run stmt with a multiple results and mapped with a SliceMapperErr.
+func (conn *db) runSliceErr(ctx context.Context, stmt Stmt, mapper SliceMapperErr) (rowsReturned int, err error) {
+	return conn.runScan(ctx, stmt, 0, func(rows *sqlx.Rows) error {
+		values, err := rows.SliceScan()
+		if err != nil {
+			return err
+		}
+		return mapper(values)
+	})
+}
+
+// runSliceRow run stmt with a single result expected and mapped with a SliceMapper.
+func (conn *db) runSliceRow(ctx context.Context, stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
+	if node := conn.replicaFor(stmt); node != nil {
+		return conn.readFrom(node, func(c *db) (int, error) {
+			return c.runSliceRow(ctx, stmt, mapper)
+		})
+	}
+
 	if err = conn.Connect(); err != nil {
 		return
 	}
 
+	ctx, cancel := conn.withTimeout(ctx)
+	defer cancel()
+
 	var (
-		stmtx  *sqlx.Stmt
-		t      time.Time
-		values = map[string]any{}
+		release func() error
+		stmtx   *sqlx.Stmt
+		values  []any
+		t       time.Time
 	)
 
 	if conn.hasProfiling() {
 		t = time.Now()
 	}
 
-	stmtx, err = preparex(conn, stmt)
+	stmtx, release, err = preparex(ctx, conn, stmt)
 	if err == nil {
-		defer stmtx.Close()
-
-		err = stmtx.QueryRowx(stmt.Args()...).MapScan(values)
+		defer release()
+		values, err = stmtx.QueryRowxContext(ctx, stmt.Args()...).SliceScan()
+		conn.markFirstRow()
 		if err == nil {
 			mapper(values)
 			rowsReturned = 1
@@ -161,6 +378,7 @@ func (conn *db) runMapRow(stmt Stmt, mapper MapMapper) (rowsReturned int, err er
 		}
 	}
 
+	err = conn.classify(err)
 	if err != nil && conn.hasVerbose() {
 		conn.logErr.Println(err.Error())
 	}
@@ -169,38 +387,61 @@ func (conn *db) runMapRow(stmt Stmt, mapper MapMapper) (rowsReturned int, err er
 	return
 }
 
-// runSlice run stmt with a multiple results and mapped with a SliceMapper.
-func (conn *db) runSlice(stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
+// runScan run stmt and call scan for each row (at most limit rows if limit > 0).
+// The iteration stops without error when scan returns ErrStop, the other errors
+// of scan are returned unchanged.
+func (conn *db) runScan(ctx context.Context, stmt Stmt, limit int, scan func(*sqlx.Rows) error) (rowsReturned int, err error) {
+	if node := conn.replicaFor(stmt); node != nil {
+		return conn.readFrom(node, func(c *db) (int, error) {
+			return c.runScan(ctx, stmt, limit, scan)
+		})
+	}
+
 	if err = conn.Connect(); err != nil {
 		return
 	}
 
+	ctx, cancel := conn.withTimeout(ctx)
+	defer cancel()
+
 	var (
-		stmtx  *sqlx.Stmt
-		rows   *sqlx.Rows
-		values []any
-		t      time.Time
+		release func() error
+		stmtx   *sqlx.Stmt
+		rows    *sqlx.Rows
+		t       time.Time
+		scanErr error
 	)
 
 	if conn.hasProfiling() {
 		t = time.Now()
 	}
 
-	stmtx, err = preparex(conn, stmt)
+	stmtx, release, err = preparex(ctx, conn, stmt)
 	if err == nil {
-		defer stmtx.Close()
-		rows, err = stmtx.Queryx(stmt.Args()...)
+		defer release()
+		rows, err = stmtx.QueryxContext(ctx, stmt.Args()...)
 		if err == nil {
 			defer rows.Close()
-			for rows.Next() {
-				values, err = rows.SliceScan()
-				if err != nil {
+			for (limit <= 0 || rowsReturned < limit) && rows.Next() {
+				conn.markFirstRow()
+				if scanErr = scan(rows); scanErr != nil {
+					if errors.Is(scanErr, ErrStop) {
+						rowsReturned++
+						scanErr = nil
+					}
 					break
 				}
-
-				mapper(values)
 				rowsReturned++
 			}
+
+			conn.markFirstRow()
+			if scanErr == nil {
+				err = rows.Err()
+			}
+
+			if err == nil && scanErr == nil && limit == 1 && rowsReturned == 0 && conn.env.ErrorNoRows {
+				err = sql.ErrNoRows
+			}
 		} else if errors.Is(err, sql.ErrNoRows) {
 			if !conn.env.ErrorNoRows {
 				err = nil
@@ -208,6 +449,10 @@ func (conn *db) runSlice(stmt Stmt, mapper SliceMapper) (rowsReturned int, err e
 		}
 	}
 
+	if err = conn.classify(err); err == nil {
+		err = scanErr
+	}
+
 	if err != nil && conn.hasVerbose() {
 		conn.logErr.Println(err.Error())
 	}
@@ -216,50 +461,86 @@ func (conn *db) runSlice(stmt Stmt, mapper SliceMapper) (rowsReturned int, err e
 	return
 }
 
-// runSliceRow run stmt with a single result expected and mapped with a SliceMapper.
-func (conn *db) runSliceRow(stmt Stmt, mapper SliceMapper) (rowsReturned int, err error) {
-	if err = conn.Connect(); err != nil {
-		return
+// preparex will prepare a query based on the given connection.
+// The returned function must be called to release the statement.
+// With Environment.StmtCache, the statements are cached per *sqlx.DB and per transaction
+// (prepared on the pinned connection of a consistent snapshot).
+func preparex(ctx context.Context, conn *db, stmt Stmt) (*sqlx.Stmt, func() error, error) {
+	query, err := conn.query(stmt)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var (
-		stmtx  *sqlx.Stmt
-		values []any
-		t      time.Time
-	)
-
-	if conn.hasProfiling() {
-		t = time.Now()
+	dbx, err := conn.pool()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	stmtx, err = preparex(conn, stmt)
-	if err == nil {
-		defer stmtx.Close()
-		if values, err = stmtx.QueryRowx(stmt.Args()...).SliceScan(); err == nil {
-			mapper(values)
-			rowsReturned = 1
-		} else if errors.Is(err, sql.ErrNoRows) {
-			if !conn.env.ErrorNoRows {
-				err = nil
-			}
+	if conn.env.StmtCache <= 0 {
+		var stmtx *sqlx.Stmt
+		if conn.tx != nil {
+			stmtx, err = conn.tx.PreparexContext(ctx, query)
+		} else {
+			stmtx, err = dbx.PreparexContext(ctx, query)
+		}
+
+		if err != nil {
+			return nil, nil, err
 		}
+		return stmtx, stmtx.Close, nil
 	}
 
-	if err != nil && conn.hasVerbose() {
-		conn.logErr.Println(err.Error())
+	cache := stmtCacheOf(dbx, conn.env.StmtCache)
+	if conn.tx == nil {
+		return cache.get(ctx, query, dbx.PreparexContext)
 	}
 
-	conn.profilingStmt(stmt, err, t)
-	return
+	return conn.txStmts.get(ctx, query, func(ctx context.Context, query string) (*sqlx.Stmt, error) {
+		tx, ok := conn.tx.(*sqlx.Tx)
+		if !ok {
+			return conn.tx.PreparexContext(ctx, query)
+		}
+
+		parent, release, err := cache.get(ctx, query, dbx.PreparexContext)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return tx.StmtxContext(ctx, parent), nil
+	})
 }
 
-// preparex will prepare a query based on the given connection.
-func preparex(conn *db, stmt Stmt) (*sqlx.Stmt, error) {
-	if conn.tx != nil {
-		return conn.tx.Preparex(stmt.String())
+// withTimeout returns ctx bounded by the statement timeout of the connection.
+func (conn *db) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if conn.env.Timeout > 0 {
+		return context.WithTimeout(ctx, conn.env.Timeout)
+	}
+	return ctx, func() {}
+}
+
+// query render the stmt with the dialect of the connection.
+// The stmt is checked against the dialect first and, in strict mode, its identifiers are validated.
+func (conn *db) query(stmt Stmt) (string, error) {
+	if l, ok := stmt.(builder.Locker); ok && l.IsLocked() && !conn.IsTx() {
+		return "", ErrLockOutsideTx
+	}
+
+	d := conn.dialect()
+	if err := builder.Check(d, stmt); err != nil {
+		return "", err
 	}
 
-	return (*conn.dbx).Preparex(stmt.String())
+	if conn.env.Strict {
+		if err := builder.Validate(d, stmt); err != nil {
+			return "", err
+		}
+	}
+
+	if conn.env.Quote {
+		d = builder.Quoted(d)
+	}
+	return builder.Render(d, stmt), nil
 }
 
 // profilingStmt store into the context the Stmt and store
@@ -274,6 +555,8 @@ func (conn *db) profilingStmt(stmt Stmt, err error, t time.Time) {
 	}
 
 	qs := &qs{
+		err:     err,
+		attempt: conn.attempt,
 		end:     time.Now(),
 		query:   stmt.String(),
 		args:    stmt.Args(),
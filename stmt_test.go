@@ -6,7 +6,16 @@
 package database
 
 import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
 )
 
 func TestMap(t *testing.T) {
@@ -21,3 +30,132 @@ func TestMapRow(t *testing.T) {
 		return
 	}
 }
+
+func TestMapErr(t *testing.T) {
+	if !TestMysql {
+		return
+	}
+
+	conn, err := OpenEnv("DSN")
+	assert.NoError(t, err)
+
+	n, err := conn.QueryMapErr("SELECT id FROM articles", func(map[string]any) error {
+		return ErrStop
+	})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, n, 1)
+
+	errMapper := errors.New("mapper")
+	_, err = conn.QuerySliceErr("SELECT id FROM articles", func([]any) error {
+		return errMapper
+	})
+	if n > 0 {
+		assert.ErrorIs(t, err, errMapper)
+	}
+}
+
+func TestUnexportedWithTimeout(t *testing.T) {
+	{
+		conn := &db{}
+		ctx, cancel := conn.withTimeout(context.Background())
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+	}
+
+	{
+		conn := &db{env: Environment{Timeout: time.Second}}
+		ctx, cancel := conn.withTimeout(context.Background())
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	}
+}
+
+func TestUnexportedExecChunks(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+	conn.env.MaxPacket = 1
+
+	i := builder.NewInsert("test")
+	i.AddRow(builder.H{"col1": "a"}, builder.H{"col1": "b"}, builder.H{"col1": "c"})
+
+	// Outside a transaction, the chunks are run in a new one.
+	{
+		_, err := conn.Exec(i)
+		assert.NoError(t, err)
+
+		query := "INSERT INTO test(col1) VALUES(?)"
+		assert.Equal(t, []string{"BEGIN", query, query, query, "COMMIT"}, fakeRecorded())
+	}
+
+	// A failing chunk rollbacks the previous ones.
+	{
+		i.Rows[1]["col1"] = "fail"
+		res, err := conn.Exec(i)
+		assert.Error(t, err)
+		assert.Nil(t, res)
+
+		queries := fakeRecorded()
+		assert.Equal(t, "BEGIN", queries[5])
+		assert.Equal(t, "ROLLBACK", queries[len(queries)-1])
+		assert.Len(t, queries, 9)
+	}
+
+	// Inside a transaction, the chunks join it.
+	{
+		i.Rows[1]["col1"] = "b"
+		assert.NoError(t, conn.RunTx(IsolationLevel, func(tx Connection) error {
+			_, err := tx.Exec(i)
+			return err
+		}))
+
+		queries := fakeRecorded()[9:]
+		assert.Equal(t, []string{"BEGIN", "COMMIT"}, []string{queries[0], queries[len(queries)-1]})
+		assert.Len(t, queries, 5)
+	}
+}
+
+func TestUnexportedMapErr(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	query := "SELECT id FROM articles"
+	fakeRows(query, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)}, []driver.Value{int64(3)})
+
+	// ErrStop stops the iteration without error.
+	{
+		n, err := conn.QueryMapErr(query, func(row map[string]any) error {
+			if row["id"] == int64(2) {
+				return ErrStop
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	}
+
+	// The error of the mapper is returned unchanged.
+	{
+		errMapper := &net.OpError{Op: "dial", Err: errors.New("mapper")}
+		n, err := conn.QuerySliceErr(query, func([]any) error {
+			return errMapper
+		})
+		assert.Same(t, errMapper, err)
+		assert.NotErrorIs(t, err, ErrConnectionLost)
+		assert.Equal(t, 0, n)
+	}
+
+	// Without error, every row is mapped.
+	{
+		ids := []any{}
+		n, err := conn.SelectSliceErr(builder.NewQuery(query), func(values []any) error {
+			ids = append(ids, values[0])
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []any{int64(1), int64(2), int64(3)}, ids)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// stmtCaches store the prepared statement cache of each *sqlx.DB.
+var stmtCaches sync.Map
+
+// StmtCacheStats is the statistics of a prepared statement cache.
+type StmtCacheStats struct {
+	Size      int
+	Len       int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// prepareFunc prepare the query.
+type prepareFunc func(ctx context.Context, query string) (*sqlx.Stmt, error)
+
+// newStmtCache create a new LRU cache of prepared statements.
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		size:  size,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+// stmtCache is a LRU cache of prepared statements keyed by query.
+// An evicted statement is closed once it is released by all its users.
+type stmtCache struct {
+	mu        sync.Mutex
+	size      int
+	ll        *list.List
+	items     map[string]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// cachedStmt is an entry of the stmtCache.
+type cachedStmt struct {
+	query   string
+	stmt    *sqlx.Stmt
+	refs    int
+	evicted bool
+}
+
+// get returns the prepared statement of query and the function to release it.
+func (c *stmtCache) get(ctx context.Context, query string, prepare prepareFunc) (*sqlx.Stmt, func() error, error) {
+	c.mu.Lock()
+	if e, ok := c.items[query]; ok {
+		c.hits++
+		c.ll.MoveToFront(e)
+		entry := e.Value.(*cachedStmt)
+		entry.refs++
+		c.mu.Unlock()
+		return entry.stmt, c.release(entry), nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	stmt, err := prepare(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the query may have been prepared concurrently.
+	if e, ok := c.items[query]; ok {
+		_ = stmt.Close()
+		c.ll.MoveToFront(e)
+		entry := e.Value.(*cachedStmt)
+		entry.refs++
+		return entry.stmt, c.release(entry), nil
+	}
+
+	entry := &cachedStmt{
+		query: query,
+		stmt:  stmt,
+		refs:  1,
+	}
+	c.items[query] = c.ll.PushFront(entry)
+
+	for c.ll.Len() > c.size {
+		c.evict(c.ll.Back())
+	}
+	return stmt, c.release(entry), nil
+}
+
+// release returns the function releasing entry.
+func (c *stmtCache) release(entry *cachedStmt) func() error {
+	var once sync.Once
+	return func() (err error) {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if entry.refs--; entry.evicted && entry.refs == 0 {
+				err = entry.stmt.Close()
+			}
+		})
+		return
+	}
+}
+
+// evict remove e from the cache, the statement is closed if unused.
+func (c *stmtCache) evict(e *list.Element) {
+	entry := c.ll.Remove(e).(*cachedStmt)
+	delete(c.items, entry.query)
+	entry.evicted = true
+	c.evictions++
+
+	if entry.refs == 0 {
+		_ = entry.stmt.Close()
+	}
+}
+
+// purge evict all the statements of the cache.
+func (c *stmtCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.ll.Back(); e != nil; e = c.ll.Back() {
+		c.evict(e)
+	}
+}
+
+// stats returns the statistics of the cache.
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return StmtCacheStats{
+		Size:      c.size,
+		Len:       c.ll.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// stmtCacheOf returns the prepared statement cache of dbx.
+func stmtCacheOf(dbx *sqlx.DB, size int) *stmtCache {
+	if c, ok := stmtCaches.Load(dbx); ok {
+		return c.(*stmtCache)
+	}
+
+	c, _ := stmtCaches.LoadOrStore(dbx, newStmtCache(size))
+	return c.(*stmtCache)
+}
+
+// purgeStmtCache purge and remove the prepared statement cache of dbx.
+func purgeStmtCache(dbx *sqlx.DB) {
+	if c, ok := stmtCaches.LoadAndDelete(dbx); ok {
+		c.(*stmtCache).purge()
+	}
+}
+
+// StmtCacheStats returns the statistics of the prepared statement cache of the connection.
+func (conn *db) StmtCacheStats() StmtCacheStats {
+	dbx := conn.load()
+	if conn.env.StmtCache <= 0 || dbx == nil {
+		return StmtCacheStats{}
+	}
+	return stmtCacheOf(dbx, conn.env.StmtCache).stats()
+}
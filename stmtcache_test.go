@@ -0,0 +1,58 @@
+// Copyright © 2019 Alexandre Kovac <contact@kovacou.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnexportedStmtCache(t *testing.T) {
+	dbx := sqlx.MustOpen("fake", "")
+	defer dbx.Close()
+
+	ctx := context.Background()
+	c := newStmtCache(2)
+
+	s1, release1, err := c.get(ctx, "SELECT 1", dbx.PreparexContext)
+	assert.NoError(t, err)
+
+	s2, release2, err := c.get(ctx, "SELECT 1", dbx.PreparexContext)
+	assert.NoError(t, err)
+	assert.Same(t, s1, s2)
+	assert.NoError(t, release2())
+
+	_, release3, err := c.get(ctx, "SELECT 2", dbx.PreparexContext)
+	assert.NoError(t, err)
+	assert.NoError(t, release3())
+
+	// "SELECT 1" is evicted but still in use.
+	closes := atomic.LoadInt32(&fakeCloses)
+	_, release4, err := c.get(ctx, "SELECT 3", dbx.PreparexContext)
+	assert.NoError(t, err)
+	assert.NoError(t, release4())
+	assert.Equal(t, closes, atomic.LoadInt32(&fakeCloses))
+
+	assert.NoError(t, release1())
+	assert.NoError(t, release1())
+	assert.Equal(t, closes+1, atomic.LoadInt32(&fakeCloses))
+
+	assert.Equal(t, StmtCacheStats{
+		Size:      2,
+		Len:       2,
+		Hits:      1,
+		Misses:    3,
+		Evictions: 1,
+	}, c.stats())
+
+	c.purge()
+	assert.Equal(t, 0, c.stats().Len)
+	assert.Equal(t, closes+3, atomic.LoadInt32(&fakeCloses))
+}
@@ -8,45 +8,212 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/xid"
+
+	"github.com/kovacou/go-database/builder"
+)
+
+// Transaction keywords.
+const (
+	savepointKeyword          = "SAVEPOINT "
+	releaseSavepointKeyword   = "RELEASE SAVEPOINT "
+	rollbackSavepointKeyword  = "ROLLBACK TO SAVEPOINT "
+	consistentSnapshotKeyword = "START TRANSACTION WITH CONSISTENT SNAPSHOT"
+	readOnlyKeyword           = ", READ ONLY"
 )
 
 // IsolationLevel is the default isolation level
 var IsolationLevel sql.IsolationLevel = sql.LevelDefault
 
+var (
+	// ErrTxTimeout is returned by Commit when the transaction has been rolled back after TxOptions.Timeout.
+	ErrTxTimeout = errors.New("database: transaction rolled back after timeout")
+
+	// ErrConsistentSnapshot is returned when a consistent snapshot is requested with an incompatible isolation level.
+	ErrConsistentSnapshot = errors.New("database: consistent snapshot not supported with this isolation level")
+
+	// ErrSavepointOptions is returned when TxOptions are given to a transaction nested into a savepoint.
+	ErrSavepointOptions = errors.New("database: transaction options not supported in a savepoint")
+)
+
 // TxFunc handler.
 type TxFunc func(Connection) error
 
+// TxOptions are the options of a transaction.
+type TxOptions struct {
+	// Isolation is the isolation level (IsolationLevel by default).
+	Isolation sql.IsolationLevel
+
+	// ReadOnly start a read-only transaction.
+	ReadOnly bool
+
+	// ConsistentSnapshot start the snapshot of the transaction immediately
+	// (START TRANSACTION WITH CONSISTENT SNAPSHOT in REPEATABLE READ on MySQL, REPEATABLE READ on PostgreSQL).
+	ConsistentSnapshot bool
+
+	// Timeout is the maximum duration of the transaction, it is rolled back automatically after.
+	Timeout time.Duration
+}
+
 // Tx copy the client and create a new transaction.
+// On a transactional connection, a savepoint is created instead (the isolation level is ignored).
 func (conn *db) Tx(level ...sql.IsolationLevel) (Connection, error) {
-	var err error
+	return conn.TxContext(context.Background(), level...)
+}
+
+// TxContext is the context version of Tx.
+// The transaction is rolled back if ctx is done before Commit or Rollback.
+func (conn *db) TxContext(ctx context.Context, level ...sql.IsolationLevel) (Connection, error) {
 	isolationLevel := IsolationLevel
 	if len(level) > 0 {
 		isolationLevel = level[0]
 	}
 
+	return conn.TxWithContext(ctx, conn.levelOptions(isolationLevel))
+}
+
+// TxWith copy the client and create a new transaction with the given options.
+// On a transactional connection, a savepoint is created instead and ErrSavepointOptions
+// is returned if opts is not empty.
+func (conn *db) TxWith(opts TxOptions) (Connection, error) {
+	return conn.TxWithContext(context.Background(), opts)
+}
+
+// TxWithContext is the context version of TxWith.
+func (conn *db) TxWithContext(ctx context.Context, opts TxOptions) (Connection, error) {
+	connTx, err := conn.beginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return connTx, nil
+}
+
+// beginTx copy the client and begin a new transaction.
+func (conn *db) beginTx(ctx context.Context, opts TxOptions) (connTx *db, err error) {
 	// try to connect to the database first.
-	if err := conn.Connect(); err != nil {
+	dbx, err := conn.pool()
+	if err != nil {
 		return nil, err
 	}
 
-	// create the transaction with the given isolation level.
-	connTx := conn.copy()
-	connTx.tx, err = (*conn.dbx).BeginTxx(context.Background(), &sql.TxOptions{
-		Isolation: isolationLevel,
-	})
+	// nest the transaction into a savepoint.
+	if conn.IsTx() {
+		if opts != (TxOptions{}) {
+			return nil, ErrSavepointOptions
+		}
+
+		connTx = conn.copy()
+		connTx.tx = conn.tx
+		connTx.txStmts = conn.txStmts
+		connTx.attempt = conn.attempt
+		connTx.savepoint = "sp_" + xid.New().String()
+		connTx.hooks = &txHooks{}
+		connTx.parentHooks = conn.hooks
+
+		if err = connTx.execSavepoint(ctx, savepointKeyword); err != nil {
+			return nil, err
+		}
+		return connTx, nil
+	}
+
+	if opts.Isolation == sql.LevelDefault {
+		opts.Isolation = IsolationLevel
+	}
+
+	var snapshot bool
+	if opts.ConsistentSnapshot {
+		switch conn.dialect() {
+		case builder.MySQL:
+			if opts.Isolation != sql.LevelDefault && opts.Isolation != sql.LevelRepeatableRead {
+				return nil, ErrConsistentSnapshot
+			}
+			snapshot = true
+		case builder.PostgreSQL:
+			switch opts.Isolation {
+			case sql.LevelDefault:
+				opts.Isolation = sql.LevelRepeatableRead
+			case sql.LevelRepeatableRead, sql.LevelSerializable:
+			default:
+				return nil, ErrConsistentSnapshot
+			}
+		}
+	}
+
+	connTx = conn.copy()
+	if opts.Timeout > 0 {
+		ctx, connTx.txCancel = context.WithTimeout(ctx, opts.Timeout)
+		connTx.txCtx = ctx
+	}
+
+	// create the transaction with the given options.
+	// database/sql can't start a consistent snapshot, it is started on a pinned connection.
+	if snapshot {
+		var tx *snapshotTx
+		if tx, err = beginSnapshot(ctx, dbx, opts.ReadOnly); err == nil {
+			connTx.tx = tx
+		}
+	} else {
+		var tx *sqlx.Tx
+		if tx, err = dbx.BeginTxx(ctx, &sql.TxOptions{
+			Isolation: opts.Isolation,
+			ReadOnly:  opts.ReadOnly,
+		}); err == nil {
+			connTx.tx = tx
+		}
+	}
 
 	if err != nil {
-		return nil, err
+		connTx.cancelTx()
+		return nil, conn.classify(err)
+	}
+
+	connTx.hooks = &txHooks{}
+	connTx.watchLeak()
+	if conn.env.StmtCache > 0 {
+		connTx.txStmts = newStmtCache(conn.env.StmtCache)
 	}
 	return connTx, nil
 }
 
 // RunTx run a bunch of TxFunc and handle the commit & rollback.
 func (conn *db) RunTx(level sql.IsolationLevel, funcs ...TxFunc) (err error) {
-	tx, err := conn.Tx(level)
+	return conn.RunTxContext(context.Background(), level, funcs...)
+}
+
+// RunTxContext is the context version of RunTx.
+func (conn *db) RunTxContext(ctx context.Context, level sql.IsolationLevel, funcs ...TxFunc) (err error) {
+	return conn.runTx(ctx, conn.levelOptions(level), 0, funcs)
+}
+
+// RunTxWith run a bunch of TxFunc in a transaction with the given options and handle the commit & rollback.
+func (conn *db) RunTxWith(opts TxOptions, funcs ...TxFunc) (err error) {
+	return conn.RunTxWithContext(context.Background(), opts, funcs...)
+}
+
+// RunTxWithContext is the context version of RunTxWith.
+func (conn *db) RunTxWithContext(ctx context.Context, opts TxOptions, funcs ...TxFunc) (err error) {
+	return conn.runTx(ctx, opts, 0, funcs)
+}
+
+// runTx run funcs in a new transaction, attempt is recorded into the profiled queries.
+// The transaction is rolled back if a func panics, then the panic is propagated.
+func (conn *db) runTx(ctx context.Context, opts TxOptions, attempt int, funcs []TxFunc) (err error) {
+	tx, err := conn.beginTx(ctx, opts)
 	if err != nil {
 		return
 	}
+	tx.attempt = attempt
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
 
 	for _, f := range funcs {
 		if err = f(tx); err != nil {
@@ -57,23 +224,99 @@ func (conn *db) RunTx(level sql.IsolationLevel, funcs ...TxFunc) (err error) {
 	return tx.Commit()
 }
 
+// levelOptions returns the options of a transaction with the isolation level,
+// the level is ignored on a transactional connection.
+func (conn *db) levelOptions(level sql.IsolationLevel) TxOptions {
+	if conn.IsTx() {
+		return TxOptions{}
+	}
+	return TxOptions{Isolation: level}
+}
+
 // IsTx says if the current connection contains a Tx.
 func (conn *db) IsTx() bool {
 	return conn.tx != nil
 }
 
-// Commit the current transation.
+// IsSavepoint says if the current connection is a transaction nested into a savepoint.
+func (conn *db) IsSavepoint() bool {
+	return conn.savepoint != ""
+}
+
+// Commit the current transation, or release the savepoint.
+// The AfterCommit hooks are run on success, the AfterRollback ones if the commit failed.
 func (conn *db) Commit() (err error) {
-	if conn.IsTx() {
-		err = conn.tx.Commit()
+	switch {
+	case conn.IsSavepoint():
+		err = conn.execSavepoint(context.Background(), releaseSavepointKeyword)
+	case conn.IsTx():
+		conn.unwatchLeak()
+		conn.closeTxStmts()
+		err = conn.classify(conn.tx.Commit())
+		if err != nil && conn.timedOut() {
+			err = ErrTxTimeout
+		}
+
+		conn.cancelTx()
+		if err != nil {
+			_ = conn.afterRollback()
+			return
+		}
+	default:
+		return
+	}
+
+	if err == nil {
+		err = conn.afterCommit()
 	}
 	return
 }
 
-// Rollback the current transaction.
+// Rollback the current transaction, or rollback to the savepoint.
+// The AfterRollback hooks are run on success.
 func (conn *db) Rollback() (err error) {
-	if conn.IsTx() {
-		err = conn.tx.Rollback()
+	switch {
+	case conn.IsSavepoint():
+		err = conn.execSavepoint(context.Background(), rollbackSavepointKeyword)
+	case conn.IsTx():
+		conn.unwatchLeak()
+		conn.closeTxStmts()
+		err = conn.classify(conn.tx.Rollback())
+		if errors.Is(err, sql.ErrTxDone) && conn.timedOut() {
+			err = nil
+		}
+		conn.cancelTx()
+	default:
+		return
+	}
+
+	if err == nil {
+		err = conn.afterRollback()
 	}
 	return
 }
+
+// timedOut says if the transaction has been rolled back after TxOptions.Timeout.
+func (conn *db) timedOut() bool {
+	return conn.txCtx != nil && errors.Is(conn.txCtx.Err(), context.DeadlineExceeded)
+}
+
+// closeTxStmts close the prepared statements of the transaction.
+func (conn *db) closeTxStmts() {
+	if conn.txStmts != nil {
+		conn.txStmts.purge()
+	}
+}
+
+// cancelTx release the context of the transaction.
+func (conn *db) cancelTx() {
+	if conn.txCancel != nil {
+		conn.txCancel()
+	}
+}
+
+// execSavepoint run the savepoint statement of the connection.
+func (conn *db) execSavepoint(ctx context.Context, keyword string) error {
+	_, err := conn.ExecContext(ctx, builder.NewQuery(keyword+conn.savepoint))
+	return err
+}
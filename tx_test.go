@@ -6,10 +6,14 @@
 package database
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/kovacou/go-database/builder"
 )
 
 func TestTx(t *testing.T) {
@@ -79,3 +83,130 @@ func TestRunTx(t *testing.T) {
 		return
 	}
 }
+
+func TestUnexportedSavepoint(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	err := conn.RunTx(IsolationLevel, func(tx Connection) error {
+		assert.False(t, tx.IsSavepoint())
+
+		assert.NoError(t, tx.RunTx(IsolationLevel, func(nested Connection) error {
+			assert.True(t, nested.IsTx())
+			assert.True(t, nested.IsSavepoint())
+			return nil
+		}))
+
+		nested, err := tx.Tx()
+		assert.NoError(t, err)
+		return nested.Rollback()
+	})
+	assert.NoError(t, err)
+
+	queries := fakeRecorded()
+	if assert.Len(t, queries, 6) {
+		assert.Equal(t, "BEGIN", queries[0])
+		assert.Regexp(t, "^SAVEPOINT sp_", queries[1])
+		assert.Equal(t, "RELEASE "+queries[1], queries[2])
+		assert.Regexp(t, "^SAVEPOINT sp_", queries[3])
+		assert.NotEqual(t, queries[1], queries[3])
+		assert.Equal(t, "ROLLBACK TO "+queries[3], queries[4])
+		assert.Equal(t, "COMMIT", queries[5])
+	}
+}
+
+func TestUnexportedRunTxPanic(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = conn.RunTx(IsolationLevel, func(Connection) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fakeRecorded())
+}
+
+func TestUnexportedTxWith(t *testing.T) {
+	conn := newFakeDB()
+	defer conn.Close()
+
+	// Consistent snapshot
+	{
+		tx, err := conn.TxWith(TxOptions{ConsistentSnapshot: true})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+		assert.Equal(t, []string{"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ", "START TRANSACTION WITH CONSISTENT SNAPSHOT", "COMMIT"}, fakeRecorded())
+		assert.ErrorIs(t, tx.Rollback(), sql.ErrTxDone)
+
+		_, err = conn.TxWith(TxOptions{ConsistentSnapshot: true, Isolation: sql.LevelReadCommitted})
+		assert.ErrorIs(t, err, ErrConsistentSnapshot)
+	}
+
+	// Consistent snapshot rolled back after the timeout, the statements run on the pinned connection
+	{
+		conn := newFakeDB()
+		defer conn.Close()
+
+		tx, err := conn.TxWith(TxOptions{ConsistentSnapshot: true, ReadOnly: true, Timeout: 10 * time.Millisecond})
+		assert.NoError(t, err)
+		_, err = tx.Exec(builder.NewQuery("UPDATE a SET b = 1"))
+		assert.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.ErrorIs(t, tx.Commit(), ErrTxTimeout)
+		assert.Equal(t, []string{
+			"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY",
+			"UPDATE a SET b = 1",
+			"ROLLBACK",
+		}, fakeRecorded())
+	}
+
+	// IsolationLevel by default
+	{
+		conn := newFakeDB()
+		defer conn.Close()
+
+		level := IsolationLevel
+		IsolationLevel = sql.LevelSerializable
+
+		assert.NoError(t, conn.RunTxWith(TxOptions{ReadOnly: true}, func(Connection) error { return nil }))
+		assert.NoError(t, conn.RunTxWith(TxOptions{Isolation: sql.LevelReadCommitted}, func(Connection) error { return nil }))
+		assert.Equal(t, []string{"BEGIN Serializable READ ONLY", "COMMIT", "BEGIN Read Committed", "COMMIT"}, fakeRecorded())
+		IsolationLevel = level
+	}
+
+	// Options in a savepoint
+	{
+		conn := newFakeDB()
+		defer conn.Close()
+
+		err := conn.RunTx(IsolationLevel, func(tx Connection) error {
+			_, err := tx.TxWith(TxOptions{ReadOnly: true})
+			assert.ErrorIs(t, err, ErrSavepointOptions)
+
+			return tx.RunTx(sql.LevelSerializable, func(Connection) error { return nil })
+		})
+		assert.NoError(t, err)
+	}
+
+	// Timeout
+	{
+		conn := newFakeDB()
+		defer conn.Close()
+
+		var rolledBack bool
+		tx, err := conn.TxWith(TxOptions{Timeout: 10 * time.Millisecond})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.AfterRollback(func() error {
+			rolledBack = true
+			return nil
+		}))
+
+		time.Sleep(50 * time.Millisecond)
+		assert.ErrorIs(t, tx.Commit(), ErrTxTimeout)
+		assert.True(t, rolledBack)
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fakeRecorded())
+	}
+}